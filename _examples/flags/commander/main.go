@@ -17,11 +17,13 @@ func main() {
 
 	app := commander.New(flag.CommandLine, appName)
 	app.Register(app.HelpCommand(), "")
+	app.Register(app.VersionCommand(), "")
 	app.Register(&printCmd{}, "")
+	app.VersionFlag("v")
 
 	flag.Parse()
 
-	os.Exit(int(app.Execute()))
+	os.Exit(commander.ExitCode(app.Execute()))
 }
 
 type printCmd struct {
@@ -36,7 +38,7 @@ func (p *printCmd) SetFlags(f *flag.FlagSet) {
 	f.BoolVar(&p.capitalize, "capitalize", false, "capitalize output")
 }
 
-func (p *printCmd) Execute(f *flag.FlagSet) commander.ExitStatus {
+func (p *printCmd) Execute(f *flag.FlagSet) error {
 	for _, arg := range f.Args() {
 		if p.capitalize {
 			arg = strings.ToUpper(arg)
@@ -44,5 +46,5 @@ func (p *printCmd) Execute(f *flag.FlagSet) commander.ExitStatus {
 		fmt.Printf("%s ", arg)
 	}
 	fmt.Println()
-	return commander.ExitSuccess
+	return nil
 }
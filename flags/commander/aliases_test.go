@@ -0,0 +1,93 @@
+package commander_test
+
+import (
+	"strings"
+	"testing"
+)
+
+type aliasCmd struct {
+	testCmd
+	aliases    []string
+	hidden     bool
+	deprecated string
+}
+
+func (c *aliasCmd) Aliases() []string  { return c.aliases }
+func (c *aliasCmd) Hidden() bool       { return c.hidden }
+func (c *aliasCmd) Deprecated() string { return c.deprecated }
+
+func TestAlias(t *testing.T) {
+	cmd := &aliasCmd{testCmd: testCmd{name: "status"}, aliases: []string{"st", "stat"}}
+	cdr, _ := newTestCommander("st", "x")
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.ran {
+		t.Fatal("want command executed through its alias")
+	}
+
+	cdr, out := newTestCommander("help", "stat")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "The status command.") || !strings.Contains(out.String(), "st, stat") {
+		t.Fatalf("want help of the canonical command, got:\n%s", out.String())
+	}
+}
+
+func TestHidden(t *testing.T) {
+	cmd := &aliasCmd{testCmd: testCmd{name: "secret"}, hidden: true}
+	cdr, out := newTestCommander("help")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "secret") {
+		t.Fatalf("want hidden command omitted, got:\n%s", out.String())
+	}
+	if got := cdr.Complete([]string{"se"}); len(got) != 0 {
+		t.Fatalf("want hidden command not completed, got %q", got)
+	}
+
+	cdr, _ = newTestCommander("secret")
+	cdr.Register(cmd, "")
+	if err := cdr.Execute(); err != nil || !cmd.ran {
+		t.Fatalf("want hidden command executed, got %v", err)
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	cmd := &aliasCmd{testCmd: testCmd{name: "rm"}, deprecated: `use "remove" instead`}
+	cdr, out := newTestCommander("rm")
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil || !cmd.ran {
+		t.Fatalf("want deprecated command executed, got %v", err)
+	}
+	if want := `Warning: command "rm" is deprecated, use "remove" instead`; !strings.Contains(out.String(), want) {
+		t.Fatalf("want %q, got:\n%s", want, out.String())
+	}
+}
+
+func TestAliasBind(t *testing.T) {
+	t.Setenv("TOOL_STATUS_VERBOSE", "true")
+
+	cmd := &aliasCmd{testCmd: testCmd{name: "status"}, aliases: []string{"st"}}
+	cdr, out := newTestCommander("st")
+	cdr.EnvPrefix = "tool"
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	if !cmd.verbose {
+		t.Fatal("want the flags bound under the canonical command name")
+	}
+}
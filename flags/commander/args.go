@@ -0,0 +1,187 @@
+package commander
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// An Arg describes a named positional argument.
+type Arg struct {
+	Name        string             // name shown in the help output, such as "file"
+	Description string             // short description of the argument
+	Validate    func(string) error // optional validator of the argument value
+}
+
+// An ArgSpec describes the positional arguments accepted by a command:
+// their count and, optionally, their names, descriptions and validators.
+// When more arguments are given than named ones, the last named argument
+// describes all the remaining ones. A zero Max means no limit when Min is
+// positive, so that ArgSpec{Min: 1} accepts one or more arguments.
+type ArgSpec struct {
+	Min  int // minimum number of arguments
+	Max  int // maximum number of arguments, or a negative number for no limit
+	Args []Arg
+}
+
+// An ArgsCommand is a Command declaring its positional arguments. The
+// Commander checks the arguments left after the flags against the spec
+// before executing the command, reporting violations as usage errors.
+type ArgsCommand interface {
+	Command
+
+	// Args returns the specification of the positional arguments.
+	Args() ArgSpec
+}
+
+// NoArgs returns an ArgSpec accepting no arguments.
+func NoArgs() ArgSpec {
+	return ArgSpec{}
+}
+
+// ExactArgs returns an ArgSpec accepting exactly n arguments.
+func ExactArgs(n int, args ...Arg) ArgSpec {
+	return ArgSpec{Min: n, Max: n, Args: args}
+}
+
+// MinArgs returns an ArgSpec accepting at least n arguments.
+func MinArgs(n int, args ...Arg) ArgSpec {
+	return ArgSpec{Min: n, Max: -1, Args: args}
+}
+
+// MaxArgs returns an ArgSpec accepting at most n arguments.
+func MaxArgs(n int, args ...Arg) ArgSpec {
+	return ArgSpec{Max: n, Args: args}
+}
+
+// RangeArgs returns an ArgSpec accepting between min and max arguments.
+func RangeArgs(min, max int, args ...Arg) ArgSpec {
+	return ArgSpec{Min: min, Max: max, Args: args}
+}
+
+// Check returns an error if args do not satisfy the spec.
+func (s ArgSpec) Check(args []string) error {
+	n, max := len(args), s.max()
+	switch {
+	case max >= 0 && s.Min == max && n != s.Min:
+		return fmt.Errorf("accepts %s, received %d", plural(s.Min, "arg"), n)
+	case n < s.Min:
+		return fmt.Errorf("requires at least %s, received %d", plural(s.Min, "arg"), n)
+	case max >= 0 && n > max:
+		return fmt.Errorf("accepts at most %s, received %d", plural(max, "arg"), n)
+	}
+
+	for i, v := range args {
+		a, ok := s.arg(i)
+		if !ok || a.Validate == nil {
+			continue
+		}
+		if err := a.Validate(v); err != nil {
+			return fmt.Errorf("invalid argument <%s> %q: %v", a.Name, v, err)
+		}
+	}
+	return nil
+}
+
+// arg returns the named argument describing the i-th argument.
+func (s ArgSpec) arg(i int) (Arg, bool) {
+	switch {
+	case len(s.Args) == 0:
+		return Arg{}, false
+	case i < len(s.Args):
+		return s.Args[i], true
+	}
+	return s.Args[len(s.Args)-1], true
+}
+
+// max returns the maximum number of arguments, or -1 for no limit.
+func (s ArgSpec) max() int {
+	if s.Max < 0 || s.Max == 0 && s.Min > 0 {
+		return -1
+	}
+	return s.Max
+}
+
+// variadic reports whether the last named argument can be repeated.
+func (s ArgSpec) variadic() bool {
+	max := s.max()
+	return max < 0 || max > len(s.Args)
+}
+
+// String returns a synopsis of the named arguments, such as
+// "<src> <dst> [<file>...]".
+func (s ArgSpec) String() string {
+	parts := make([]string, 0, len(s.Args))
+	for i, a := range s.Args {
+		name := "<" + a.Name + ">"
+		if i == len(s.Args)-1 && s.variadic() {
+			name += "..."
+		}
+		if i >= s.Min {
+			name = "[" + name + "]"
+		}
+		parts = append(parts, name)
+	}
+	return strings.Join(parts, " ")
+}
+
+// argRows returns the help rows of the named arguments of a spec with
+// their descriptions.
+func argRows(s ArgSpec) []HelpRow {
+	var rows []HelpRow
+	for i, a := range s.Args {
+		name := "<" + a.Name + ">"
+		if i == len(s.Args)-1 && s.variadic() {
+			name += "..."
+		}
+		desc := a.Description
+		if i >= s.Min {
+			desc = strings.TrimSpace(desc + " (optional)")
+		}
+		rows = append(rows, HelpRow{"   " + name, desc})
+	}
+	return rows
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// ExistingFile is an Arg validator accepting paths of existing files.
+func ExistingFile(v string) error {
+	fi, err := os.Stat(v)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("%s is a directory", v)
+	}
+	return nil
+}
+
+// ExistingDir is an Arg validator accepting paths of existing directories.
+func ExistingDir(v string) error {
+	fi, err := os.Stat(v)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", v)
+	}
+	return nil
+}
+
+// OneOf returns an Arg validator accepting only the given choices.
+func OneOf(choices ...string) func(string) error {
+	return func(v string) error {
+		for _, c := range choices {
+			if c == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of [%s]", strings.Join(choices, " "))
+	}
+}
@@ -0,0 +1,100 @@
+package commander_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type argsCmd struct {
+	testCmd
+	spec commander.ArgSpec
+}
+
+func (c *argsCmd) Args() commander.ArgSpec { return c.spec }
+
+func TestArgSpecCheck(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+
+	tests := []struct {
+		spec commander.ArgSpec
+		args []string
+		ok   bool
+	}{
+		{commander.NoArgs(), nil, true},
+		{commander.NoArgs(), []string{"a"}, false},
+		{commander.ExactArgs(2), []string{"a", "b"}, true},
+		{commander.ExactArgs(2), []string{"a"}, false},
+		{commander.MinArgs(1), []string{"a", "b", "c"}, true},
+		{commander.MinArgs(1), nil, false},
+		{commander.MaxArgs(1), []string{"a", "b"}, false},
+		{commander.ArgSpec{Min: 1}, []string{"a", "b", "c"}, true},
+		{commander.ArgSpec{Min: 1}, nil, false},
+		{commander.ArgSpec{}, []string{"a"}, false},
+		{commander.RangeArgs(1, 2), []string{"a", "b"}, true},
+		{commander.RangeArgs(1, 2), []string{"a", "b", "c"}, false},
+		{commander.MinArgs(1, commander.Arg{Name: "dir", Validate: commander.ExistingDir}), []string{dir, dir}, true},
+		{commander.MinArgs(1, commander.Arg{Name: "dir", Validate: commander.ExistingDir}), []string{dir, missing}, false},
+		{commander.ExactArgs(1, commander.Arg{Name: "mode", Validate: commander.OneOf("fast", "exact")}), []string{"slow"}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.spec.Check(tt.args); (err == nil) != tt.ok {
+			t.Errorf("%d: Check(%q): want ok=%v, got %v", i, tt.args, tt.ok, err)
+		}
+	}
+}
+
+func TestArgSpecString(t *testing.T) {
+	spec := commander.MinArgs(1,
+		commander.Arg{Name: "dst"},
+		commander.Arg{Name: "src"},
+	)
+	if got, want := spec.String(), "<dst> [<src>...]"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	spec = commander.ArgSpec{Min: 1, Args: []commander.Arg{{Name: "file"}}}
+	if got, want := spec.String(), "<file>..."; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestExecuteArgs(t *testing.T) {
+	newCmd := func() *argsCmd {
+		return &argsCmd{
+			testCmd: testCmd{name: "copy"},
+			spec: commander.ExactArgs(2,
+				commander.Arg{Name: "src", Description: "the source file"},
+				commander.Arg{Name: "dst", Description: "the destination file"},
+			),
+		}
+	}
+
+	cmd := newCmd()
+	cdr, out := newTestCommander("copy", "a")
+	cdr.Register(cmd, "")
+
+	err := cdr.Execute()
+	if !errors.Is(err, commander.ErrInvalidArgs) || !errors.Is(err, commander.ErrUsage) {
+		t.Fatalf("want invalid args usage error, got %v", err)
+	}
+	if cmd.ran {
+		t.Fatal("want command not executed")
+	}
+	for _, want := range []string{"copy: accepts 2 args, received 1", "Arguments:", "<src>", "the destination file"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in:\n%s", want, out.String())
+		}
+	}
+
+	cmd = newCmd()
+	cdr, _ = newTestCommander("copy", "a", "b")
+	cdr.Register(cmd, "")
+	if err := cdr.Execute(); err != nil || !cmd.ran {
+		t.Fatalf("want command executed, got %v", err)
+	}
+}
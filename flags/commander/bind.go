@@ -0,0 +1,200 @@
+package commander
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lucasepe/toolbox/env"
+	"github.com/lucasepe/toolbox/text"
+	"github.com/lucasepe/toolbox/xdg"
+)
+
+// Sources of a flag value, from the highest to the lowest precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceConfig  = "config"
+	SourceDefault = "default"
+)
+
+// ConfigPath returns the default location of the config file of the
+// named program: "<name>/config.env" under xdg.ConfigDir().
+func ConfigPath(name string) string {
+	return filepath.Join(xdg.ConfigDir(), name, "config.env")
+}
+
+// configKey returns the key used to look up a flag in the config file:
+// the command path and the flag name in SCREAMING_SNAKE_CASE, such as
+// REMOTE_TIMEOUT for the "timeout" flag of the "remote" command.
+func configKey(path []string, name string) string {
+	parts := append(path[:len(path):len(path)], name)
+	return text.ToScreamingSnake(strings.Join(parts, "_"))
+}
+
+// envName returns the environment variable bound to a flag, or the
+// empty string when EnvPrefix is not set.
+func (cdr *Commander) envName(path []string, name string) string {
+	if cdr.EnvPrefix == "" {
+		return ""
+	}
+	return text.ToScreamingSnake(cdr.EnvPrefix) + "_" + configKey(path, name)
+}
+
+// loadConfig reads the config file, unless already loaded. A missing file is not an error.
+func (cdr *Commander) loadConfig() (map[string]string, error) {
+	if cdr.ConfigFile == "" {
+		return nil, nil
+	}
+	if cdr.config == nil {
+		m, err := env.FromFile(cdr.ConfigFile)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("reading config file %s: %w", cdr.ConfigFile, err)
+		}
+		if m == nil {
+			m = map[string]string{}
+		}
+		cdr.config = m
+	}
+	return cdr.config, nil
+}
+
+// lookupFlag returns the value bound to a flag in the environment or in
+// the config file, and its source. It returns SourceDefault when the
+// flag is not bound to any value.
+func (cdr *Commander) lookupFlag(path []string, name string) (value, source string, err error) {
+	if key := cdr.envName(path, name); key != "" {
+		if v, ok := cdr.LookupEnv(key); ok {
+			return v, SourceEnv, nil
+		}
+	}
+
+	config, err := cdr.loadConfig()
+	if err != nil {
+		return "", SourceDefault, err
+	}
+	if v, ok := config[configKey(path, name)]; ok {
+		return v, SourceConfig, nil
+	}
+	return "", SourceDefault, nil
+}
+
+// bind sets the flags of fs not given on the command line from the
+// environment or from the config file. The path holds the names of the
+// commands owning fs, and is empty for the top-level flags. The
+// inherited persistent flags are bound with the top-level flags, so they
+// are skipped.
+func (cdr *Commander) bind(fs *flag.FlagSet, path []string, inherited map[string]bool) error {
+	invalid, err := cdr.bindFlags(fs, path, inherited)
+	if err == nil {
+		fs.VisitAll(func(f *flag.Flag) {
+			if err == nil && invalid[f.Name] != nil {
+				err = invalid[f.Name]
+			}
+		})
+	}
+	if err != nil {
+		return &UsageError{Kind: ErrFlagParse, Command: strings.Join(path, " "), Err: err}
+	}
+	return nil
+}
+
+// bindFlags is like bind, but sets all the flags bound to a valid value
+// and returns the errors of the others by flag name. Its error reports a
+// config file that cannot be read.
+func (cdr *Commander) bindFlags(fs *flag.FlagSet, path []string, inherited map[string]bool) (map[string]error, error) {
+	if cdr.EnvPrefix == "" && cdr.ConfigFile == "" {
+		return nil, nil
+	}
+
+	set := setFlags(fs)
+
+	var err error
+	invalid := map[string]error{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] || inherited[f.Name] || isShorthand(f) {
+			return
+		}
+		value, source, lerr := cdr.lookupFlag(path, f.Name)
+		if lerr != nil {
+			err = lerr
+			return
+		}
+		if source == SourceDefault {
+			return
+		}
+		if serr := fs.Set(f.Name, value); serr != nil {
+			invalid[f.Name] = fmt.Errorf("invalid value %q for flag -%s from %s: %v", value, f.Name, source, serr)
+		}
+	})
+	return invalid, err
+}
+
+// An envTagger binds some of its flags to environment variables of its
+// own, as the env tags of FromStruct do. The map holds the variable
+// names by flag name.
+type envTagger interface {
+	flagEnv() map[string]string
+}
+
+// bindTags sets the flags of fs not given on the command line from the
+// environment variables bound by cmd, when it is an envTagger. They take
+// precedence over the values bound by bind.
+func (cdr *Commander) bindTags(cmd Command, fs *flag.FlagSet, path []string) error {
+	et, ok := cmd.(envTagger)
+	if !ok {
+		return nil
+	}
+	set := setFlags(fs)
+
+	env := et.flagEnv()
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if set[name] {
+			continue
+		}
+		value, ok := cdr.LookupEnv(env[name])
+		if !ok {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			err = fmt.Errorf("invalid value %q for flag -%s from $%s: %v", value, name, env[name], err)
+			return &UsageError{Kind: ErrFlagParse, Command: strings.Join(path, " "), Err: err}
+		}
+	}
+	return nil
+}
+
+// annotator returns a function describing how the flags of a command,
+// or the top-level flags when path is empty, are bound: the environment
+// variable name and, when bound, the effective value and its source.
+// The flags in invalid, as returned by bindFlags, are shown with their
+// default value instead. It returns nil when no binding is configured.
+func (cdr *Commander) annotator(path []string, invalid map[string]error) func(*flag.Flag) string {
+	if cdr.EnvPrefix == "" && cdr.ConfigFile == "" {
+		return nil
+	}
+	return func(f *flag.Flag) string {
+		var notes []string
+		if key := cdr.envName(path, f.Name); key != "" {
+			notes = append(notes, "$"+key)
+		}
+		if _, source, _ := cdr.lookupFlag(path, f.Name); invalid[f.Name] != nil {
+			notes = append(notes, fmt.Sprintf("invalid value from %s, using the default", source))
+		} else if source != SourceDefault {
+			notes = append(notes, fmt.Sprintf("%s from %s", f.Value, source))
+		}
+		if len(notes) == 0 {
+			return ""
+		}
+		return "[" + strings.Join(notes, ", ") + "]"
+	}
+}
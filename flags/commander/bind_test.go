@@ -0,0 +1,166 @@
+package commander_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type timeoutCmd struct {
+	testCmd
+	timeout time.Duration
+	retries int
+	name    string
+}
+
+func (c *timeoutCmd) SetFlags(f *flag.FlagSet) {
+	f.DurationVar(&c.timeout, "timeout", time.Second, "request timeout")
+	f.IntVar(&c.retries, "retries", 1, "number of retries")
+	f.StringVar(&c.name, "remote-name", "origin", "name of the remote")
+}
+
+func TestBindEnvAndConfig(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.env")
+	err := os.WriteFile(config, []byte("REMOTE_TIMEOUT=5s\nREMOTE_RETRIES=3\nREMOTE_REMOTE_NAME=upstream\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("MYTOOL_REMOTE_RETRIES", "7")
+	t.Setenv("MYTOOL_REMOTE_REMOTE_NAME", "fork")
+
+	cmd := &timeoutCmd{testCmd: testCmd{name: "remote"}}
+	cdr, _ := newTestCommander("remote", "-remote-name", "mine")
+	cdr.EnvPrefix = "mytool"
+	cdr.ConfigFile = config
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.timeout != 5*time.Second {
+		t.Errorf("want timeout from config, got %v", cmd.timeout)
+	}
+	if cmd.retries != 7 {
+		t.Errorf("want retries from env, got %v", cmd.retries)
+	}
+	if cmd.name != "mine" {
+		t.Errorf("want remote-name from command line, got %v", cmd.name)
+	}
+}
+
+func TestBindTopLevel(t *testing.T) {
+	t.Setenv("MYTOOL_VERBOSE", "true")
+
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "be verbose")
+	fs.Parse([]string{"run"})
+
+	cdr := commander.New(fs, "tool")
+	cdr.EnvPrefix = "MYTOOL"
+	cdr.Register(&testCmd{name: "run"}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose {
+		t.Fatal("want top-level flag set from env")
+	}
+}
+
+func TestBindInvalidValue(t *testing.T) {
+	t.Setenv("MYTOOL_REMOTE_TIMEOUT", "soon")
+
+	cdr, _ := newTestCommander("remote")
+	cdr.EnvPrefix = "MYTOOL"
+	cdr.Register(&timeoutCmd{testCmd: testCmd{name: "remote"}}, "")
+
+	if code := commander.ExitCode(cdr.Execute()); code != commander.ExitUsageError {
+		t.Fatalf("want usage error, got exit code %d", code)
+	}
+}
+
+func TestBindHelp(t *testing.T) {
+	t.Setenv("MYTOOL_REMOTE_RETRIES", "7")
+
+	cdr, out := newTestCommander("help", "remote")
+	cdr.EnvPrefix = "MYTOOL"
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&timeoutCmd{testCmd: testCmd{name: "remote"}}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"$MYTOOL_REMOTE_TIMEOUT", "[$MYTOOL_REMOTE_RETRIES, 7 from env]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in help, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBindHelpInvalidValue(t *testing.T) {
+	t.Setenv("MYTOOL_REMOTE_TIMEOUT", "soon")
+	t.Setenv("MYTOOL_REMOTE_RETRIES", "7")
+
+	cdr, out := newTestCommander("help", "remote")
+	cdr.EnvPrefix = "MYTOOL"
+	cdr.Width = 200
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&timeoutCmd{testCmd: testCmd{name: "remote"}}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`invalid value "soon" for flag -timeout from env`,
+		"[$MYTOOL_REMOTE_TIMEOUT, invalid value from env, using the default]",
+		"[$MYTOOL_REMOTE_RETRIES, 7 from env]",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in help, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestBindConfigReload(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.env")
+	for _, retries := range []int{3, 5} {
+		if err := os.WriteFile(config, []byte(fmt.Sprintf("REMOTE_RETRIES=%d\n", retries)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		cmd := &timeoutCmd{testCmd: testCmd{name: "remote"}}
+		cdr, _ := newTestCommander("remote")
+		cdr.ConfigFile = config
+		cdr.Register(cmd, "")
+		if err := cdr.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if cmd.retries != retries {
+			t.Errorf("want retries %d from config, got %d", retries, cmd.retries)
+		}
+	}
+}
+
+func TestCommanderPrintDefaults(t *testing.T) {
+	t.Setenv("MYTOOL_REMOTE_RETRIES", "7")
+
+	cdr, out := newTestCommander()
+	cdr.EnvPrefix = "MYTOOL"
+
+	fs := flag.NewFlagSet("remote", flag.ContinueOnError)
+	fs.SetOutput(out)
+	(&timeoutCmd{}).SetFlags(fs)
+	fs.Set("retries", "7")
+	cdr.PrintDefaults(fs, "Flags:\n", "remote")
+
+	for _, want := range []string{"$MYTOOL_REMOTE_TIMEOUT", "[$MYTOOL_REMOTE_RETRIES, 7 from env]"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in defaults, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -3,12 +3,18 @@
 package commander
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"sort"
-	"text/tabwriter"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lucasepe/toolbox/text"
 )
 
 // A Command represents a single command.
@@ -30,6 +36,48 @@ type Command interface {
 	Execute(f *flag.FlagSet) error
 }
 
+// A Subcommander is a Command that owns its own child commands, such
+// as "remote" in "tool remote add". Commander.Execute parses the
+// command's flags and, when the first remaining argument names one of
+// its children, dispatches to that child with its own flag set.
+// Otherwise the command's own Execute method is called.
+type Subcommander interface {
+	Command
+
+	// Subcommands returns the child commands.
+	Subcommands() []Command
+}
+
+// An Aliaser is a Command that can also be invoked by other names,
+// such as the names it had before being renamed.
+type Aliaser interface {
+	Command
+
+	// Aliases returns the alternative names of the command.
+	Aliases() []string
+}
+
+// A Hider is a Command that can be left out of the help output, the
+// generated documentation and the shell completion. Hidden commands
+// can still be executed.
+type Hider interface {
+	Command
+
+	// Hidden reports whether the command is hidden.
+	Hidden() bool
+}
+
+// A Deprecator is a Command that can be deprecated. Deprecated commands
+// still run, but a warning is printed first (see WarnDeprecated).
+type Deprecator interface {
+	Command
+
+	// Deprecated returns a message pointing to the replacement of the
+	// command, such as `use "remote add" instead`, or the empty string
+	// if the command is not deprecated.
+	Deprecated() string
+}
+
 // A CommandGroup represents a set of commands about a common topic.
 type CommandGroup struct {
 	name     string
@@ -38,18 +86,98 @@ type CommandGroup struct {
 
 // A Commander represents a set of commands.
 type Commander struct {
-	commands  []*CommandGroup
-	topFlags  *flag.FlagSet // top-level flags
-	important []string      // important top-level flags
-	name      string        // normally path.Base(os.Args[0])
+	commands   []*CommandGroup
+	topFlags   *flag.FlagSet // top-level flags
+	important  []string      // important top-level flags
+	persistent []string      // persistent top-level flags
+	name       string        // normally path.Base(os.Args[0])
 
 	Banner         string                         // A banner.
 	Explain        func(io.Writer)                // A function to print a top level usage explanation. Can be overridden.
 	ExplainGroup   func(io.Writer, *CommandGroup) // A function to print a command group's usage explanation. Can be overridden.
 	ExplainCommand func(io.Writer, Command)       // A function to print a command usage explanation. Can be overridden.
-
+	WarnDeprecated func(io.Writer, Command)       // A function to print the warning for a deprecated command. Can be overridden.
+
+	// UsageTemplate, GroupTemplate and CommandTemplate are the
+	// text/template templates rendered by the default Explain,
+	// ExplainGroup and ExplainCommand (see DefaultUsageTemplate).
+	UsageTemplate   string
+	GroupTemplate   string
+	CommandTemplate string
+
+	// Width is the width the help output is wrapped to. Zero means the
+	// width of the terminal, or 80 columns when the output is not a
+	// terminal.
+	Width int
+
+	// Color, when set, renders the headings of the help output in bold.
+	// ANSI escape sequences are stripped from the help output when it is
+	// not written to a terminal.
+	Color bool
+
+	Input  io.Reader // Input specifies where the commander should read its input (default: os.Stdin).
 	Output io.Writer // Output specifies where the commander should write its output (default: os.Stdout).
 	Error  io.Writer // Error specifies where the commander should write its error (default: os.Stderr).
+
+	// LookupEnv looks up the environment variables bound to flags and
+	// the $PATH searched for plugins (default: os.LookupEnv).
+	LookupEnv func(key string) (string, bool)
+
+	// GracePeriod is how long ExecuteContext waits for a command to
+	// return after the first interrupt before exiting. Zero means to
+	// wait until the command returns or a second interrupt arrives.
+	GracePeriod time.Duration
+
+	// EnvPrefix, when set, binds every flag to an environment variable
+	// named after the prefix, the command path and the flag name in
+	// SCREAMING_SNAKE_CASE, such as MYTOOL_REMOTE_TIMEOUT for the
+	// "timeout" flag of the "remote" command of "mytool".
+	EnvPrefix string
+
+	// ConfigFile, when set, is the path of a config file holding
+	// KEY=value lines, where the keys are the environment variable names
+	// without the prefix, such as REMOTE_TIMEOUT. See ConfigPath.
+	// Values given on the command line take precedence over the
+	// environment, which takes precedence over the config file. The
+	// file is read again by every call to Execute and every line of a
+	// Shell session.
+	ConfigFile string
+
+	// Plugins, when set, dispatches unknown command names to external
+	// executables named "<name>-<command>", found in PluginDir or on
+	// $PATH, and lists them in the PluginGroup of the help output.
+	Plugins bool
+
+	// BuildInfo, when set, is the build information printed by the
+	// VersionCommand and the VersionFlag flags and reported by Describe,
+	// instead of the one returned by ReadBuildInfo.
+	BuildInfo *BuildInfo
+
+	// Interspersed, when set, allows flags to follow the positional
+	// arguments of every command, up to a "--" terminator, as in
+	// "tool build ./x -verbose". All the top-level flags are then also
+	// accepted after the command name, like persistent flags (see
+	// PersistentFlag).
+	Interspersed bool
+
+	// GNUFlags, when set, parses the command lines of the commands with
+	// GNU conventions: one-letter shorthands (see Shorthand) and boolean
+	// flags can be combined, as in "-xvf archive" or "-vvv", and boolean
+	// flags can be negated, as in "--no-color". Long flags are accepted
+	// with one or two dashes.
+	GNUFlags bool
+
+	// Prompt is the prompt of the interactive session started by Shell
+	// (default: the commander name followed by "> ").
+	Prompt string
+
+	middleware      []Middleware            // global middleware
+	groupMiddleware map[string][]Middleware // middleware by group name
+	inShell         bool                    // whether Shell is running
+	config          map[string]string       // contents of ConfigFile, loaded once per Execute or Shell line
+	plugins         []Command               // discovered plugins, once loaded
+	exit            func(code int)          // normally os.Exit
+	version         *bool                   // the value of the VersionFlag flags
 }
 
 // Name returns the group name
@@ -62,15 +190,22 @@ func (g *CommandGroup) Name() string {
 // will be set as well.
 func New(topLevelFlags *flag.FlagSet, name string) *Commander {
 	cdr := &Commander{
-		topFlags: topLevelFlags,
-		name:     name,
-		Output:   os.Stdout,
-		Error:    os.Stderr,
+		topFlags:  topLevelFlags,
+		name:      name,
+		Input:     os.Stdin,
+		Output:    os.Stdout,
+		Error:     os.Stderr,
+		LookupEnv: os.LookupEnv,
+		exit:      os.Exit,
 	}
 
 	cdr.Explain = cdr.explain
-	cdr.ExplainGroup = explainGroup
-	cdr.ExplainCommand = explain
+	cdr.ExplainGroup = cdr.explainGroup
+	cdr.ExplainCommand = cdr.explainCommand
+	cdr.WarnDeprecated = cdr.warnDeprecated
+	cdr.UsageTemplate = DefaultUsageTemplate
+	cdr.GroupTemplate = DefaultGroupTemplate
+	cdr.CommandTemplate = DefaultCommandTemplate
 	topLevelFlags.Usage = func() { cdr.Explain(cdr.Error) }
 	return cdr
 }
@@ -147,38 +282,332 @@ func (cdr *Commander) VisitAll(fn func(*flag.Flag)) {
 
 // Execute should be called once the top-level-flags on a Commander
 // have been initialized. It finds the correct subcommand and executes
-// it, and returns an ExitStatus with the result. On a usage error, an
-// appropriate message is printed to os.Stderr, and ExitUsageError is
-// returned. The additional args are provided as-is to the Execute method
-// of the selected Command.
+// it, and returns the error of the command. On a usage error, an
+// appropriate message is printed to the Error writer, and a *UsageError
+// is returned. The additional args are provided as-is to the Execute
+// method of the selected Command. Use ExitCode to turn the returned
+// error into a process exit code.
 func (cdr *Commander) Execute() error {
-	if cdr.topFlags.NArg() < 1 {
+	return cdr.execute(context.Background())
+}
+
+// execute dispatches the top-level arguments, passing ctx to the
+// selected command when it is a ContextCommand.
+func (cdr *Commander) execute(ctx context.Context) error {
+	cdr.config = nil // reload the config file
+	if err := cdr.bind(cdr.topFlags, nil, nil); err != nil {
+		fmt.Fprintln(cdr.Error, err)
+		return err
+	}
+	if cdr.version != nil && *cdr.version {
+		return cdr.writeVersion(cdr.Output, false)
+	}
+	return cdr.dispatch(ctx, cdr.topFlags.Args())
+}
+
+// dispatch finds the command named by the first of args and runs it
+// with the remaining ones.
+func (cdr *Commander) dispatch(ctx context.Context, args []string) error {
+	ctx = context.WithValue(ctx, stdioKey{}, cdr)
+	if len(args) < 1 {
 		cdr.topFlags.Usage()
-		return nil
+		return &UsageError{Kind: ErrUsage, Err: errNoCommand}
+	}
+
+	name := args[0]
+	cmd := cdr.lookup(name)
+	if cmd == nil {
+		// Cannot find this command.
+		err := &UsageError{
+			Kind:        ErrUnknownCommand,
+			Command:     name,
+			Suggestions: suggestCommands(name, subcommandsOf(cdr, nil)),
+		}
+		fmt.Fprintln(cdr.Error, err)
+		cdr.topFlags.Usage()
+		return err
+	}
+	return cdr.run(ctx, cmd, []string{cmd.Name()}, args[1:])
+}
+
+// run parses args with the flags of cmd and executes it, descending into
+// a child command when cmd is a Subcommander and the first remaining
+// argument names one of its children. The path holds the names of the
+// commands resolved so far.
+func (cdr *Commander) run(ctx context.Context, cmd Command, path []string, args []string) error {
+	if isDeprecated(cmd) {
+		cdr.WarnDeprecated(cdr.Error, cmd)
+	}
+
+	f := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	if _, ok := cmd.(*plugin); ok {
+		// Plugins parse their own flags.
+		return cdr.invoke(ctx, &Invocation{
+			Command:  cmd,
+			Path:     path,
+			Group:    PluginGroup,
+			Flags:    f,
+			TopFlags: cdr.topFlags,
+			Args:     args,
+		})
+	}
+
+	f.SetOutput(cdr.Error)
+	f.Usage = func() { cdr.ExplainCommand(cdr.Error, cmd) }
+	cmd.SetFlags(f)
+	inherited := cdr.inheritFlags(f)
+	isCommand := func(name string) bool { return false }
+	if sc, ok := cmd.(Subcommander); ok {
+		isCommand = func(name string) bool { return findCommand(sc.Subcommands(), name) != nil }
+	}
+	if err := cdr.parseArgs(f, args, isCommand); err != nil {
+		uerr := &UsageError{Kind: ErrFlagParse, Command: f.Name(), Err: err}
+		if uerr.Suggestions = suggestFlags(f, err); len(uerr.Suggestions) > 0 {
+			fmt.Fprintf(cdr.Error, "Did you mean %s?\n", uerr.Suggestions[0])
+		}
+		return uerr
+	}
+	if err := cdr.bindTags(cmd, f, path); err != nil {
+		fmt.Fprintln(cdr.Error, err)
+		return err
+	}
+	if err := cdr.bind(f, path, inherited); err != nil {
+		fmt.Fprintln(cdr.Error, err)
+		return err
+	}
+	if err := checkConstraints(cmd, f); err != nil {
+		uerr := &UsageError{Kind: ErrInvalidFlags, Command: f.Name(), Err: err}
+		fmt.Fprintln(cdr.Error, uerr)
+		f.Usage()
+		return uerr
+	}
+
+	if sc, ok := cmd.(Subcommander); ok && f.NArg() > 0 {
+		if child := findCommand(sc.Subcommands(), f.Arg(0)); child != nil {
+			return cdr.run(ctx, child, append(path, child.Name()), f.Args()[1:])
+		}
+	}
+
+	if ac, ok := cmd.(ArgsCommand); ok {
+		if err := ac.Args().Check(f.Args()); err != nil {
+			uerr := &UsageError{Kind: ErrInvalidArgs, Command: f.Name(), Err: err}
+			fmt.Fprintln(cdr.Error, uerr)
+			f.Usage()
+			return uerr
+		}
 	}
 
-	name := cdr.topFlags.Arg(0)
+	return cdr.invokeWithSignals(ctx, &Invocation{
+		Command:  cmd,
+		Path:     path,
+		Group:    cdr.groupOf(path[0]),
+		Flags:    f,
+		TopFlags: cdr.topFlags,
+		Args:     f.Args(),
+	})
+}
 
+// lookup returns the top-level command or plugin with the given name,
+// or nil.
+func (cdr *Commander) lookup(name string) Command {
+	if cmd := cdr.lookupRegistered(name); cmd != nil {
+		return cmd
+	}
+	return findCommand(cdr.pluginCommands(), name)
+}
+
+// lookupRegistered returns the registered top-level command with the
+// given name, or nil.
+func (cdr *Commander) lookupRegistered(name string) Command {
 	for _, group := range cdr.commands {
-		for _, cmd := range group.commands {
-			if name != cmd.Name() {
-				continue
+		if cmd := findCommand(group.commands, name); cmd != nil {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// lookupPath resolves a sequence of command names, such as
+// ["remote", "add"], descending through Subcommanders. It returns nil
+// if any name along the path is unknown.
+func (cdr *Commander) lookupPath(names []string) Command {
+	if len(names) == 0 {
+		return nil
+	}
+	cmd := cdr.lookup(names[0])
+	for _, name := range names[1:] {
+		sc, ok := cmd.(Subcommander)
+		if !ok {
+			return nil
+		}
+		if cmd = findCommand(sc.Subcommands(), name); cmd == nil {
+			return nil
+		}
+	}
+	return cmd
+}
+
+// suggestPath returns the command paths closest to names, fixing the
+// first unknown name along the path.
+func (cdr *Commander) suggestPath(names []string) []string {
+	cmds := subcommandsOf(cdr, nil)
+	for i, name := range names {
+		cmd := findCommand(cmds, name)
+		if cmd == nil {
+			var res []string
+			for _, s := range suggestCommands(name, cmds) {
+				res = append(res, strings.Join(append(names[:i:i], s), " "))
+			}
+			return res
+		}
+		cmds = subcommandsOf(cdr, cmd)
+	}
+	return nil
+}
+
+// sameCommand reports whether a and b are the same command: the same
+// pointer, or equal values. Values that cannot be compared with ==, such
+// as a struct holding a slice, directly or in an interface field, match
+// by type and name instead.
+func sameCommand(a, b Command) (same bool) {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	if va.Kind() == reflect.Pointer {
+		return va.Pointer() == vb.Pointer()
+	}
+	defer func() {
+		if recover() != nil {
+			same = a.Name() == b.Name()
+		}
+	}()
+	return a == b
+}
+
+// commandPath returns the full path of names leading to cmd, starting
+// with the commander's name, or nil if cmd is not registered.
+func (cdr *Commander) commandPath(cmd Command) []string {
+	var walk func(cmds []Command, path []string) []string
+	walk = func(cmds []Command, path []string) []string {
+		for _, c := range cmds {
+			p := append(path[:len(path):len(path)], c.Name())
+			if sameCommand(c, cmd) {
+				return p
 			}
-			f := flag.NewFlagSet(name, flag.ContinueOnError)
-			f.Usage = func() { cdr.ExplainCommand(cdr.Error, cmd) }
-			cmd.SetFlags(f)
-			if f.Parse(cdr.topFlags.Args()[1:]) != nil {
-				return nil
+			if sc, ok := c.(Subcommander); ok {
+				if found := walk(sc.Subcommands(), p); found != nil {
+					return found
+				}
 			}
-			return cmd.Execute(f)
 		}
+		return nil
 	}
 
-	// Cannot find this command.
-	cdr.topFlags.Usage()
+	for _, group := range cdr.commands {
+		if path := walk(group.commands, []string{cdr.name}); path != nil {
+			return path
+		}
+	}
 	return nil
 }
 
+// suggestCommands returns the names of the visible commands closest to
+// name, matching their aliases too.
+func suggestCommands(name string, cmds []Command) []string {
+	var names []string
+	canonical := make(map[string]string)
+	for _, cmd := range cmds {
+		if isHidden(cmd) {
+			continue
+		}
+		names = append(names, cmd.Name())
+		for _, alias := range aliasesOf(cmd) {
+			names = append(names, alias)
+			canonical[alias] = cmd.Name()
+		}
+	}
+
+	var res []string
+	seen := make(map[string]bool)
+	for _, s := range text.Suggest(name, names, 0) {
+		if c, ok := canonical[s]; ok {
+			s = c
+		}
+		if !seen[s] {
+			seen[s] = true
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// suggestFlags returns the flags of f closest to the undefined flag
+// reported by err, if any.
+func suggestFlags(f *flag.FlagSet, err error) []string {
+	const undefined = "flag provided but not defined: -"
+	msg := err.Error()
+	if !strings.HasPrefix(msg, undefined) {
+		return nil
+	}
+	name := strings.TrimLeft(strings.TrimPrefix(msg, undefined), "-")
+
+	var names []string
+	f.VisitAll(func(fl *flag.Flag) {
+		names = append(names, fl.Name)
+	})
+
+	return suggestFlagNames(name, names)
+}
+
+// suggestFlagNames returns the flag names closest to name, with a
+// leading dash.
+func suggestFlagNames(name string, names []string) []string {
+	res := text.Suggest(name, names, 0)
+	for i := range res {
+		res[i] = "-" + res[i]
+	}
+	return res
+}
+
+// findCommand returns the command in cmds with the given name or
+// alias, or nil. Names take precedence over aliases.
+func findCommand(cmds []Command, name string) Command {
+	for _, cmd := range cmds {
+		if cmd.Name() == name {
+			return cmd
+		}
+	}
+	for _, cmd := range cmds {
+		for _, alias := range aliasesOf(cmd) {
+			if alias == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// aliasesOf returns the aliases of cmd, if it is an Aliaser.
+func aliasesOf(cmd Command) []string {
+	if a, ok := cmd.(Aliaser); ok {
+		return a.Aliases()
+	}
+	return nil
+}
+
+// isHidden reports whether cmd is a hidden command.
+func isHidden(cmd Command) bool {
+	h, ok := cmd.(Hider)
+	return ok && h.Hidden()
+}
+
+// isDeprecated reports whether cmd is a deprecated command.
+func isDeprecated(cmd Command) bool {
+	d, ok := cmd.(Deprecator)
+	return ok && d.Deprecated() != ""
+}
+
 // countFlags returns the number of top-level flags defined, even those not set.
 func (cdr *Commander) countTopFlags() int {
 	count := 0
@@ -197,39 +626,32 @@ func (p byGroupName) Less(i, j int) bool { return p[i].name < p[j].name }
 func (p byGroupName) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
 
 // explain prints a brief description of all the subcommands and the
-// important top-level flags.
+// important top-level flags, rendering the UsageTemplate.
 func (cdr *Commander) explain(w io.Writer) {
-	if len(cdr.Banner) > 0 {
-		fmt.Fprintln(w, cdr.Banner)
-	}
-	fmt.Fprint(w, "Usage:\n")
-	fmt.Fprintf(w, "   %s <command>\n\n", cdr.name)
-
 	sort.Sort(byGroupName(cdr.commands))
-	for _, group := range cdr.commands {
-		cdr.ExplainGroup(w, group)
+	data := UsageHelp{
+		Name:     cdr.name,
+		Banner:   cdr.Banner,
+		Groups:   cdr.commands,
+		HasFlags: cdr.countTopFlags() > 0,
 	}
-	if cdr.topFlags == nil {
-		fmt.Fprintln(w, "\nNo top level flags.")
-		return
+	if plugins := cdr.pluginCommands(); len(plugins) > 0 {
+		data.Groups = append(data.Groups[:len(data.Groups):len(data.Groups)],
+			&CommandGroup{name: PluginGroup, commands: plugins})
 	}
 
-	sort.Strings(cdr.important)
-	if len(cdr.important) == 0 {
-		if cdr.countTopFlags() > 0 {
-			fmt.Fprintf(w, "\nUse \"%s flags\" for a list of top-level flags\n", cdr.name)
-		}
-		return
-	}
+	annotate := withDefaults(cdr.annotator(nil, nil))
+	cdr.VisitAllImportant(func(f *flag.Flag) {
+		data.ImportantFlags = append(data.ImportantFlags, flagRow(f, nil, annotate))
+	})
 
-	fmt.Fprintf(w, "\nTop-level flags (use \"%s flags\" for a full list):\n", cdr.name)
-	for _, name := range cdr.important {
-		f := cdr.topFlags.Lookup(name)
-		if f == nil {
-			panic(fmt.Sprintf("Important flag (%s) is not defined", name))
-		}
-		fmt.Fprintf(w, "  -%s=%s: %s\n", f.Name, f.DefValue, f.Usage)
-	}
+	cdr.render(w, cdr.UsageTemplate, data, template.FuncMap{
+		"explainGroup": func(g *CommandGroup) string {
+			gw := &helpWriter{width: cdr.widthOf(w), tty: isTerminal(w)}
+			cdr.ExplainGroup(gw, g)
+			return gw.String()
+		},
+	})
 }
 
 // Sorting of the commands within a group.
@@ -237,39 +659,96 @@ func (g CommandGroup) Len() int           { return len(g.commands) }
 func (g CommandGroup) Less(i, j int) bool { return g.commands[i].Name() < g.commands[j].Name() }
 func (g CommandGroup) Swap(i, j int)      { g.commands[i], g.commands[j] = g.commands[j], g.commands[i] }
 
-// explainGroup explains all the commands for a particular group.
-func explainGroup(w io.Writer, group *CommandGroup) {
-	if len(group.commands) == 0 {
-		return
-	}
-	if group.name == "" {
-		fmt.Fprintf(w, "Commands:\n")
-	} else {
-		fmt.Fprintf(w, "Commands for %s:\n", group.name)
-	}
+// explainGroup explains all the commands for a particular group,
+// rendering the GroupTemplate.
+func (cdr *Commander) explainGroup(w io.Writer, group *CommandGroup) {
 	sort.Sort(group)
-
-	tw := tabwriter.NewWriter(w, 0, 3, 3, ' ', 0)
-
+	data := GroupHelp{Name: group.name}
 	for _, cmd := range group.commands {
-		fmt.Fprintf(tw, "   %s\t%s\n", cmd.Name(), cmd.Synopsis())
+		if !isHidden(cmd) {
+			data.Commands = append(data.Commands, HelpRow{"   " + cmd.Name(), synopsisOf(cmd)})
+		}
 	}
-	tw.Flush()
-	fmt.Fprintln(w)
+	if len(data.Commands) == 0 {
+		return
+	}
+	cdr.render(w, cdr.GroupTemplate, data, nil)
 }
 
-// explainCmd prints a brief description of a single command.
-func explain(w io.Writer, cmd Command) {
-	fmt.Fprintf(w, "Synopsis:\n")
-	fmt.Fprintf(w, "   %s\n\n", cmd.Synopsis())
+// explainCommand prints a brief description of a single command,
+// followed by its child commands when it is a Subcommander, rendering
+// the CommandTemplate.
+func (cdr *Commander) explainCommand(w io.Writer, cmd Command) {
+	path := cdr.commandPath(cmd)
+	if path == nil {
+		path = []string{cdr.name, cmd.Name()}
+	}
 
-	fmt.Fprintf(w, "Usage:\n")
-	fmt.Fprintf(w, "   %s\n\n", cmd.Usage())
+	data := CommandHelp{
+		Name:        cmd.Name(),
+		Path:        strings.Join(path, " "),
+		Synopsis:    cmd.Synopsis(),
+		Usage:       cmd.Usage(),
+		Aliases:     aliasesOf(cmd),
+		Constraints: constraintLines(cmd),
+		HelpCommand: strings.Join(append([]string{cdr.name, "help"}, path[1:]...), " "),
+	}
+	if ac, ok := cmd.(ArgsCommand); ok {
+		data.Args = argRows(ac.Args())
+	}
+	if d, ok := cmd.(Deprecator); ok {
+		data.Deprecated = d.Deprecated()
+	}
 
 	subflags := flag.NewFlagSet(cmd.Name(), flag.PanicOnError)
-	subflags.SetOutput(w)
+	subflags.SetOutput(io.Discard)
 	cmd.SetFlags(subflags)
-	PrintDefaults(subflags, "Flags:\n")
+	invalid, err := cdr.bindFlags(subflags, path[1:], nil)
+	if err != nil {
+		data.Warnings = append(data.Warnings, err.Error())
+	}
+	subflags.VisitAll(func(f *flag.Flag) {
+		if invalid[f.Name] != nil {
+			data.Warnings = append(data.Warnings, invalid[f.Name].Error())
+		}
+	})
+	data.Flags = flagRows(subflags.VisitAll, annotateRequired(cmd, cdr.annotator(path[1:], invalid)))
+
+	global := flag.NewFlagSet(cdr.name, flag.PanicOnError)
+	for _, f := range cdr.persistentFlags() {
+		if subflags.Lookup(f.Name) == nil {
+			global.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	data.GlobalFlags = flagRows(global.VisitAll, cdr.annotator(nil, nil))
+
+	if sc, ok := cmd.(Subcommander); ok {
+		for _, child := range sc.Subcommands() {
+			if !isHidden(child) {
+				data.Subcommands = append(data.Subcommands,
+					HelpRow{"   " + data.Path + " " + child.Name(), synopsisOf(child)})
+			}
+		}
+	}
+
+	cdr.render(w, cdr.CommandTemplate, data, nil)
+}
+
+// synopsisOf returns the synopsis of cmd, marked when it is deprecated.
+func synopsisOf(cmd Command) string {
+	if isDeprecated(cmd) {
+		return cmd.Synopsis() + " (deprecated)"
+	}
+	return cmd.Synopsis()
+}
+
+// warnDeprecated prints a warning about the use of a deprecated command.
+func (cdr *Commander) warnDeprecated(w io.Writer, cmd Command) {
+	name := cmd.Name()
+	if path := cdr.commandPath(cmd); path != nil {
+		name = strings.Join(path[1:], " ")
+	}
+	fmt.Fprintf(w, "Warning: command %q is deprecated, %s\n", name, cmd.(Deprecator).Deprecated())
 }
 
 // A helper is a Command implementing a "help" command for
@@ -282,34 +761,48 @@ func (h *helper) Synopsis() string {
 }
 func (h *helper) SetFlags(*flag.FlagSet) {}
 func (h *helper) Usage() string {
-	return `help [<command>]
+	return `help [<command> [<subcommand>...]]
 	
-With an argument, prints detailed information on the use of
-the specified command. With no argument, print a list of
-all commands and a brief description of each.
+With arguments, prints detailed information on the use of
+the specified command or subcommand. With no argument, print a list of
+all commands and a brief description of each. "help flags" prints
+all the top-level flags.
 `
 }
 func (h *helper) Execute(f *flag.FlagSet) error {
-	switch f.NArg() {
-	case 0:
+	if f.NArg() == 0 {
 		(*Commander)(h).Explain(h.Output)
 		return nil
+	}
 
-	case 1:
-		for _, group := range h.commands {
-			for _, cmd := range group.commands {
-				if f.Arg(0) != cmd.Name() {
-					continue
-				}
-				(*Commander)(h).ExplainCommand(h.Output, cmd)
-				return nil
-			}
+	if f.Arg(0) == "flags" {
+		cdr := (*Commander)(h)
+		matches, err := cdr.matchTopFlags(f.Args()[1:])
+		if err != nil {
+			fmt.Fprintln(h.Error, err)
+			return err
 		}
-		fmt.Fprintf(h.Error, "Subcommand %s not understood\n", f.Arg(0))
+		cdr.writeFlags(h.Output, matches)
+		return nil
+	}
+
+	if cmd := (*Commander)(h).lookupPath(f.Args()); cmd != nil {
+		(*Commander)(h).ExplainCommand(h.Output, cmd)
+		return nil
+	}
+	err := &UsageError{
+		Kind:        ErrUnknownCommand,
+		Command:     strings.Join(f.Args(), " "),
+		Suggestions: (*Commander)(h).suggestPath(f.Args()),
 	}
+	fmt.Fprintf(h.Error, "Subcommand %s not understood", err.Command)
+	if len(err.Suggestions) > 0 {
+		fmt.Fprintf(h.Error, ", did you mean %q?", err.Suggestions[0])
+	}
+	fmt.Fprintln(h.Error)
 
 	f.Usage()
-	return nil
+	return err
 }
 
 // HelpCommand returns a Command which implements a "help" subcommand.
@@ -0,0 +1,164 @@
+package commander_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type testCmd struct {
+	name     string
+	children []commander.Command
+	verbose  bool
+	ran      bool
+	args     []string
+}
+
+func (c *testCmd) Name() string     { return c.name }
+func (c *testCmd) Synopsis() string { return "The " + c.name + " command." }
+func (c *testCmd) Usage() string    { return c.name + " [-verbose] <args>" }
+
+func (c *testCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.verbose, "verbose", false, "be verbose")
+}
+
+func (c *testCmd) Execute(f *flag.FlagSet) error {
+	c.ran = true
+	c.args = f.Args()
+	return nil
+}
+
+type parentCmd struct {
+	testCmd
+}
+
+func (c *parentCmd) Subcommands() []commander.Command { return c.children }
+
+func newTestCommander(args ...string) (*commander.Commander, *bytes.Buffer) {
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	fs.Parse(args)
+
+	var out bytes.Buffer
+	cdr := commander.New(fs, "tool")
+	cdr.Output = &out
+	cdr.Error = &out
+	return cdr, &out
+}
+
+func TestExecuteSubcommand(t *testing.T) {
+	add := &testCmd{name: "add"}
+	rm := &testCmd{name: "rm"}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add, rm}}}
+
+	cdr, _ := newTestCommander("remote", "-verbose", "add", "-verbose", "origin")
+	cdr.Register(remote, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if remote.ran || rm.ran {
+		t.Fatal("only the leaf command should run")
+	}
+	if !add.ran || !add.verbose || !remote.verbose {
+		t.Fatal("want flags parsed at each level and the leaf executed")
+	}
+	if strings.Join(add.args, " ") != "origin" {
+		t.Fatalf("want args [origin], got %v", add.args)
+	}
+}
+
+func TestExecuteSubcommanderFallback(t *testing.T) {
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{&testCmd{name: "add"}}}}
+
+	cdr, _ := newTestCommander("remote", "list")
+	cdr.Register(remote, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !remote.ran || strings.Join(remote.args, " ") != "list" {
+		t.Fatal("want the parent to run with the unmatched argument")
+	}
+}
+
+func TestHelpSubcommand(t *testing.T) {
+	add := &testCmd{name: "add"}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add}}}
+
+	cdr, out := newTestCommander("help", "remote")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(remote, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "tool remote add") {
+		t.Fatalf("want full command path in help, got:\n%s", out.String())
+	}
+
+	cdr, out = newTestCommander("help", "remote", "add")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(remote, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "The add command.") {
+		t.Fatalf("want help for the nested command, got:\n%s", out.String())
+	}
+}
+
+// A valueCmd is a Command of an uncomparable non-pointer type.
+type valueCmd struct {
+	tags []string
+}
+
+func (c valueCmd) Name() string                  { return "tag" }
+func (c valueCmd) Synopsis() string              { return "Tag things." }
+func (c valueCmd) Usage() string                 { return "tag" }
+func (c valueCmd) SetFlags(f *flag.FlagSet)      {}
+func (c valueCmd) Execute(f *flag.FlagSet) error { return nil }
+func (c valueCmd) Deprecated() string            { return "use label instead" }
+
+// An anyCmd is a Command of a comparable type which may hold an
+// uncomparable value.
+type anyCmd struct {
+	data interface{}
+}
+
+func (c anyCmd) Name() string                  { return "any" }
+func (c anyCmd) Synopsis() string              { return "Hold anything." }
+func (c anyCmd) Usage() string                 { return "any" }
+func (c anyCmd) SetFlags(f *flag.FlagSet)      {}
+func (c anyCmd) Execute(f *flag.FlagSet) error { return nil }
+
+func TestUncomparableCommand(t *testing.T) {
+	for _, args := range [][]string{{"help", "tag"}, {"tag"}} {
+		cdr, out := newTestCommander(args...)
+		cdr.Register(cdr.HelpCommand(), "")
+		cdr.Register(valueCmd{tags: []string{"a"}}, "")
+
+		if err := cdr.Execute(); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		if !strings.Contains(out.String(), "use label instead") {
+			t.Fatalf("%v: want the deprecation shown, got:\n%s", args, out.String())
+		}
+	}
+}
+
+func TestUncomparableInterfaceField(t *testing.T) {
+	cdr, out := newTestCommander("help", "any")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(anyCmd{data: []string{"a"}}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Hold anything.") {
+		t.Fatalf("want the help of the command, got:\n%s", out.String())
+	}
+}
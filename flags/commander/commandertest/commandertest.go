@@ -0,0 +1,137 @@
+// Package commandertest runs commander programs in-process, for table
+// driven tests of command lines.
+//
+// Every run builds a fresh Commander, with its own top-level flags,
+// environment and standard streams, so that runs share no global state
+// and tests can call t.Parallel. The commands under test should print
+// to the Output and Error of the Commander, for example through
+// commander.Stdio, rather than to os.Stdout and os.Stderr. The Setup
+// should set the BuildInfo of the Commander when the output includes the
+// program version, which otherwise depends on the build of the test.
+package commandertest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+// Update, when set, makes Golden write the golden files instead of
+// comparing with them. The package defines no flag of its own, so that it
+// does not clash with those of the importing tests, which typically bind
+// it to theirs:
+//
+//	func init() {
+//		flag.BoolVar(&commandertest.Update, "update", false, "update the golden files")
+//	}
+var Update bool
+
+// A Setup returns the Commander under test, built with the top-level
+// flags fs, which are parsed afterwards. It is called once per run.
+type Setup func(fs *flag.FlagSet) *commander.Commander
+
+// A Case is a command line to run.
+type Case struct {
+	Args  []string          // the arguments, without the program name
+	Env   map[string]string // the environment overrides
+	Stdin string            // the standard input
+}
+
+// A Result is the outcome of a run.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int   // the exit code, as computed by commander.ExitCode
+	Err      error // the error returned by Execute
+}
+
+// Width is the width of the help output of the runs, unless the Setup
+// sets one, so that the output does not depend on the terminal.
+const Width = 80
+
+// Run runs the command line args with the Commander returned by setup.
+func Run(setup Setup, args ...string) Result {
+	return Case{Args: args}.Run(setup)
+}
+
+// Run runs the case with the Commander returned by setup. The
+// environment variables are looked up in Env first, then in the
+// environment of the process.
+func (c Case) Run(setup Setup) Result {
+	var stdout, stderr bytes.Buffer
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	fs.SetOutput(&stderr)
+
+	cdr := setup(fs)
+	cdr.Input = strings.NewReader(c.Stdin)
+	cdr.Output = &stdout
+	cdr.Error = &stderr
+	cdr.LookupEnv = func(key string) (string, bool) {
+		if v, ok := c.Env[key]; ok {
+			return v, true
+		}
+		return os.LookupEnv(key)
+	}
+	if cdr.Width == 0 {
+		cdr.Width = Width
+	}
+
+	err := fs.Parse(c.Args)
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		err = &commander.UsageError{Kind: commander.ErrFlagParse, Err: err}
+	}
+	if err == nil {
+		err = cdr.Execute()
+	}
+	return Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: commander.ExitCode(err),
+		Err:      err,
+	}
+}
+
+// Golden compares got with the contents of the golden file
+// testdata/name.golden, reporting a difference as a test failure. When
+// Update is set, it writes got to the file instead.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (set Update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s:\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// GoldenHelp runs "help" followed by the command path, which requires
+// the Commander to register its HelpCommand, and compares the output with
+// the golden file testdata/name.golden (see Golden).
+func GoldenHelp(t testing.TB, setup Setup, name string, path ...string) {
+	t.Helper()
+
+	res := Run(setup, append([]string{"help"}, path...)...)
+	if res.Err != nil {
+		t.Fatalf("help %s: %v\n%s", strings.Join(path, " "), res.Err, res.Stderr)
+	}
+	Golden(t, name, res.Stdout+res.Stderr)
+}
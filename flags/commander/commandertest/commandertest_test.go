@@ -0,0 +1,88 @@
+package commandertest_test
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+	"github.com/lucasepe/toolbox/flags/commander/commandertest"
+)
+
+func init() {
+	flag.BoolVar(&commandertest.Update, "update", false, "update the golden files")
+}
+
+type greetCmd struct {
+	greeting string
+}
+
+func (c *greetCmd) Name() string     { return "greet" }
+func (c *greetCmd) Synopsis() string { return "Greet the names read from the standard input." }
+func (c *greetCmd) Usage() string    { return "greet [-greeting text]" }
+
+func (c *greetCmd) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&c.greeting, "greeting", "Hello", "`text` the greeting")
+}
+
+func (c *greetCmd) Execute(f *flag.FlagSet) error {
+	return c.Run(context.Background(), f)
+}
+
+func (c *greetCmd) Run(ctx context.Context, f *flag.FlagSet) error {
+	in, out, _ := commander.Stdio(ctx)
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		fmt.Fprintf(out, "%s, %s!\n", c.greeting, sc.Text())
+	}
+	return sc.Err()
+}
+
+func setup(fs *flag.FlagSet) *commander.Commander {
+	cdr := commander.New(fs, "greeter")
+	cdr.EnvPrefix = "GREETER"
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&greetCmd{}, "")
+	return cdr
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		c    commandertest.Case
+		out  string
+		code int
+	}{
+		{"stdin", commandertest.Case{Args: []string{"greet"}, Stdin: "Ada\nBob\n"}, "Hello, Ada!\nHello, Bob!\n", 0},
+		{"flag", commandertest.Case{Args: []string{"greet", "-greeting", "Hi"}, Stdin: "Ada"}, "Hi, Ada!\n", 0},
+		{"env", commandertest.Case{Args: []string{"greet"}, Env: map[string]string{"GREETER_GREET_GREETING": "Ciao"}, Stdin: "Ada"}, "Ciao, Ada!\n", 0},
+		{"unknown", commandertest.Case{Args: []string{"great"}}, "", commander.ExitUsageError},
+		{"bad top-level flag", commandertest.Case{Args: []string{"-nope"}}, "", commander.ExitUsageError},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			res := tt.c.Run(setup)
+			if res.Stdout != tt.out || res.ExitCode != tt.code {
+				t.Fatalf("want %q and exit code %d, got %q and %d (%v)\n%s",
+					tt.out, tt.code, res.Stdout, res.ExitCode, res.Err, res.Stderr)
+			}
+		})
+	}
+}
+
+func TestRunStderr(t *testing.T) {
+	res := commandertest.Run(setup, "great")
+	if !strings.Contains(res.Stderr, `did you mean "greet"?`) {
+		t.Fatalf("want a suggestion on stderr, got:\n%s", res.Stderr)
+	}
+}
+
+func TestGoldenHelp(t *testing.T) {
+	commandertest.GoldenHelp(t, setup, "help")
+	commandertest.GoldenHelp(t, setup, "help-greet", "greet")
+}
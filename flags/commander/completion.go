@@ -0,0 +1,296 @@
+package commander
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lucasepe/toolbox/flags"
+)
+
+// An ArgsCompleter is a Command that can suggest values for its
+// positional arguments during shell completion.
+type ArgsCompleter interface {
+	// CompleteArgs returns the candidates for the positional argument
+	// being typed. The args are the positional arguments already given,
+	// and toComplete is the (possibly empty) partial word.
+	CompleteArgs(args []string, toComplete string) []string
+}
+
+// A FlagCompleter is a Command that can suggest values for its flags
+// during shell completion.
+type FlagCompleter interface {
+	// CompleteFlag returns the candidates for the value of the named flag,
+	// given the (possibly empty) partial word toComplete.
+	CompleteFlag(name, toComplete string) []string
+}
+
+// completeArg is the argument of the completion command used by the
+// generated shell scripts to query the candidates for a command line.
+const completeArg = "__complete"
+
+// A completion is a Command implementing a "completion" command for
+// a given Commander.
+type completion Commander
+
+func (c *completion) Name() string { return "completion" }
+func (c *completion) Synopsis() string {
+	return "Generate the autocompletion script for the specified shell."
+}
+func (c *completion) SetFlags(*flag.FlagSet) {}
+func (c *completion) Usage() string {
+	return `completion <bash|zsh|fish>
+
+Prints the completion script for the given shell. For example:
+
+  source <(` + c.name + ` completion bash)
+  ` + c.name + ` completion zsh > "${fpath[1]}/_` + c.name + `"
+  ` + c.name + ` completion fish > ~/.config/fish/completions/` + c.name + `.fish
+`
+}
+func (c *completion) Execute(f *flag.FlagSet) error {
+	if f.NArg() > 0 && f.Arg(0) == completeArg {
+		for _, s := range (*Commander)(c).Complete(f.Args()[1:]) {
+			fmt.Fprintln(c.Output, s)
+		}
+		return nil
+	}
+
+	if f.NArg() == 1 {
+		switch f.Arg(0) {
+		case "bash":
+			return writeScript(c.Output, bashCompletion, c.name)
+		case "zsh":
+			return writeScript(c.Output, zshCompletion, c.name)
+		case "fish":
+			return writeScript(c.Output, fishCompletion, c.name)
+		}
+		fmt.Fprintf(c.Error, "Shell %s not supported\n", f.Arg(0))
+	}
+
+	f.Usage()
+	return &UsageError{Kind: ErrUsage, Command: c.Name()}
+}
+
+// CompletionCommand returns a Command which implements a "completion"
+// subcommand, printing bash, zsh or fish completion scripts. The scripts
+// call back into the program to compute the candidates, so that they
+// never drift from the registered commands and flags.
+func (cdr *Commander) CompletionCommand() Command {
+	return (*completion)(cdr)
+}
+
+// Complete returns the completion candidates for a command line. The
+// args are the words following the program name; the last one is the
+// (possibly empty) word being completed.
+func (cdr *Commander) Complete(args []string) []string {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	words, toComplete := args[:len(args)-1], args[len(args)-1]
+
+	var (
+		cmd        Command
+		fs         = cdr.topFlags
+		positional []string
+		terminated bool
+	)
+	if fs == nil {
+		fs = flag.NewFlagSet(cdr.name, flag.ContinueOnError)
+	}
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !terminated && (len(positional) == 0 || cdr.Interspersed) && isFlag(word) {
+			if word == "--" {
+				terminated = true
+				continue
+			}
+			name, _, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+				i++ // skip the flag value
+			}
+			continue
+		}
+
+		if len(positional) == 0 {
+			if child := findCommand(subcommandsOf(cdr, cmd), word); child != nil {
+				cmd = child
+				fs = flag.NewFlagSet(child.Name(), flag.ContinueOnError)
+				fs.SetOutput(io.Discard)
+				child.SetFlags(fs)
+				cdr.inheritFlags(fs)
+				terminated = false
+				continue
+			}
+		}
+		positional = append(positional, word)
+	}
+
+	// Complete the value of a flag given as a separate word.
+	flagsAllowed := !terminated && (len(positional) == 0 || cdr.Interspersed)
+	if flagsAllowed && len(words) > 0 {
+		prev := words[len(words)-1]
+		if isFlag(prev) && !strings.Contains(prev, "=") {
+			if f := fs.Lookup(strings.TrimLeft(prev, "-")); f != nil && !isBoolFlag(f) {
+				return completeFlagValue(cmd, f, toComplete, "")
+			}
+		}
+	}
+
+	// Complete a flag name or a value given as -name=value.
+	if flagsAllowed && strings.HasPrefix(toComplete, "-") {
+		dashes := "-"
+		if strings.HasPrefix(toComplete, "--") {
+			dashes = "--"
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(toComplete, "-"), "=")
+		if hasValue {
+			if f := fs.Lookup(name); f != nil {
+				return completeFlagValue(cmd, f, value, dashes+name+"=")
+			}
+			return nil
+		}
+
+		var res []string
+		fs.VisitAll(func(f *flag.Flag) {
+			if strings.HasPrefix(f.Name, name) {
+				res = append(res, dashes+f.Name)
+			}
+		})
+		return res
+	}
+
+	var res []string
+	if len(positional) == 0 {
+		for _, c := range subcommandsOf(cdr, cmd) {
+			if !isHidden(c) && strings.HasPrefix(c.Name(), toComplete) {
+				res = append(res, c.Name())
+			}
+		}
+		sort.Strings(res)
+	}
+	if ac, ok := cmd.(ArgsCompleter); ok {
+		res = append(res, ac.CompleteArgs(positional, toComplete)...)
+	}
+	return res
+}
+
+// subcommandsOf returns the commands reachable from cmd, or the
+// top-level commands when cmd is nil.
+func subcommandsOf(cdr *Commander, cmd Command) []Command {
+	if cmd == nil {
+		var res []Command
+		for _, group := range cdr.commands {
+			res = append(res, group.commands...)
+		}
+		return append(res, cdr.pluginCommands()...)
+	}
+	if sc, ok := cmd.(Subcommander); ok {
+		return sc.Subcommands()
+	}
+	return nil
+}
+
+// completeFlagValue returns the candidates for the value of flag f,
+// each one prefixed with prefix.
+func completeFlagValue(cmd Command, f *flag.Flag, toComplete, prefix string) []string {
+	var candidates []string
+	if choices := choicesOf(f.Value); choices != nil {
+		for _, c := range choices {
+			if strings.HasPrefix(strings.ToLower(c), strings.ToLower(toComplete)) {
+				candidates = append(candidates, c)
+			}
+		}
+	} else if fc, ok := cmd.(FlagCompleter); ok {
+		candidates = fc.CompleteFlag(f.Name, toComplete)
+	}
+
+	res := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		res = append(res, prefix+c)
+	}
+	return res
+}
+
+// choicesOf returns the names of the valid choices of the flag.Values
+// implementing flags.Chooser, like the enum types of the flags package,
+// or nil for any other flag.Value.
+func choicesOf(v flag.Value) []string {
+	if s, ok := v.(*shorthand); ok {
+		v = s.Value
+	}
+	c, ok := v.(flags.Chooser)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, info := range c.ValidChoices() {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func isFlag(word string) bool {
+	return len(word) > 1 && word[0] == '-'
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	bf, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeScript writes a completion script for the program name.
+func writeScript(w io.Writer, script, name string) error {
+	r := strings.NewReplacer(
+		"{{name}}", name,
+		"{{func}}", nonIdentifier.ReplaceAllString(name, "_"),
+		"{{complete}}", completeArg,
+	)
+	_, err := io.WriteString(w, r.Replace(script))
+	return err
+}
+
+const bashCompletion = `# bash completion for {{name}}
+
+_{{func}}_completions() {
+    local IFS=$'\n'
+    local words=("${COMP_WORDS[@]:1:COMP_CWORD}")
+    COMPREPLY=($({{name}} completion {{complete}} "${words[@]}" 2>/dev/null))
+}
+
+complete -o default -F _{{func}}_completions {{name}}
+`
+
+const zshCompletion = `#compdef {{name}}
+
+# zsh completion for {{name}}
+
+_{{func}}() {
+    local -a completions
+    completions=("${(@f)$({{name}} completion {{complete}} "${(@)words[2,$CURRENT]}" 2>/dev/null)}")
+    completions=(${completions:#})
+    if (( ${#completions} )); then
+        compadd -a completions
+    else
+        _files
+    fi
+}
+
+compdef _{{func}} {{name}}
+`
+
+const fishCompletion = `# fish completion for {{name}}
+
+function __{{func}}_complete
+    set -l args (commandline -opc)[2..-1] (commandline -ct)
+    {{name}} completion {{complete}} $args 2>/dev/null
+end
+
+complete -c {{name}} -f -a '(__{{func}}_complete)'
+`
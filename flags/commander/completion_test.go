@@ -0,0 +1,71 @@
+package commander_test
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type enumCmd struct {
+	testCmd
+	mode flags.Enum
+}
+
+func (c *enumCmd) SetFlags(f *flag.FlagSet) {
+	c.testCmd.SetFlags(f)
+	c.mode = flags.Enum{Choices: []string{"fast", "exact"}}
+	f.Var(&c.mode, "mode", "select a mode")
+}
+
+func (c *enumCmd) CompleteArgs(args []string, toComplete string) []string {
+	return []string{"arg" + toComplete}
+}
+
+func TestComplete(t *testing.T) {
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{
+		&testCmd{name: "add"}, &testCmd{name: "rm"},
+	}}}
+
+	cdr, _ := newTestCommander()
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cdr.CompletionCommand(), "")
+	cdr.Register(remote, "")
+	cdr.Register(&enumCmd{testCmd: testCmd{name: "run"}}, "")
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"re"}, []string{"remote"}},
+		{[]string{"remote", ""}, []string{"add", "rm"}},
+		{[]string{"remote", "-verbose", "a"}, []string{"add"}},
+		{[]string{"run", "-"}, []string{"-mode", "-verbose"}},
+		{[]string{"run", "-mode", "f"}, []string{"fast"}},
+		{[]string{"run", "-mode=e"}, []string{"-mode=exact"}},
+		{[]string{"run", "x"}, []string{"argx"}},
+	}
+	for _, tt := range tests {
+		got := cdr.Complete(tt.args)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q): want %q, got %q", tt.args, tt.want, got)
+		}
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		cdr, out := newTestCommander("completion", shell)
+		cdr.Register(cdr.CompletionCommand(), "")
+
+		if err := cdr.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "tool completion __complete") {
+			t.Errorf("%s: want a callback into the program, got:\n%s", shell, out.String())
+		}
+	}
+}
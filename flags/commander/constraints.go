@@ -0,0 +1,216 @@
+package commander
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// A constraintKind is the kind of a Constraint.
+type constraintKind int
+
+const (
+	required constraintKind = iota
+	mutuallyExclusive
+	exactlyOne
+	allOrNone
+	dependsOn
+)
+
+// A Constraint is a rule on the flags set on the command line, from the
+// environment or from the config file (see EnvPrefix and ConfigFile).
+type Constraint struct {
+	kind  constraintKind
+	Flags []string // the flags subject to the rule
+	Deps  []string // the flags required by the flags, for DependsOn
+}
+
+// A ConstrainedCommand is a Command declaring constraints on its flags.
+// The Commander checks them once the flags are parsed, before executing
+// the command, reporting violations as usage errors. The help output
+// marks the required flags and lists the other constraints.
+type ConstrainedCommand interface {
+	Command
+
+	// Constraints returns the constraints on the flags of the command.
+	Constraints() []Constraint
+}
+
+// Required returns a Constraint requiring each of the named flags.
+func Required(names ...string) Constraint {
+	return Constraint{kind: required, Flags: names}
+}
+
+// MutuallyExclusive returns a Constraint allowing at most one of the
+// named flags.
+func MutuallyExclusive(names ...string) Constraint {
+	return Constraint{kind: mutuallyExclusive, Flags: names}
+}
+
+// ExactlyOne returns a Constraint requiring exactly one of the named
+// flags.
+func ExactlyOne(names ...string) Constraint {
+	return Constraint{kind: exactlyOne, Flags: names}
+}
+
+// AllOrNone returns a Constraint requiring the named flags to be set
+// together or not at all.
+func AllOrNone(names ...string) Constraint {
+	return Constraint{kind: allOrNone, Flags: names}
+}
+
+// DependsOn returns a Constraint requiring the deps flags whenever the
+// named flag is set.
+func DependsOn(name string, deps ...string) Constraint {
+	return Constraint{kind: dependsOn, Flags: []string{name}, Deps: deps}
+}
+
+// Check returns an error if the flags set in fs do not satisfy the
+// constraint. It panics if the constraint names a flag not defined in fs.
+func (c Constraint) Check(fs *flag.FlagSet) error {
+	set := setFlags(fs)
+
+	var given, missing []string
+	for _, name := range append(c.Flags[:len(c.Flags):len(c.Flags)], c.Deps...) {
+		if fs.Lookup(name) == nil {
+			panic(fmt.Sprintf("Constraint on an undefined flag (%s)", name))
+		}
+	}
+	for _, name := range c.Flags {
+		if set[name] {
+			given = append(given, name)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+
+	switch c.kind {
+	case required:
+		if len(missing) == 1 {
+			return fmt.Errorf("required flag %s not set", flagList(missing, ""))
+		}
+		if len(missing) > 1 {
+			return fmt.Errorf("required flags %s not set", flagList(missing, ", "))
+		}
+	case mutuallyExclusive:
+		if len(given) > 1 {
+			return fmt.Errorf("flags %s cannot be used together", flagList(given, " and "))
+		}
+	case exactlyOne:
+		if len(given) == 0 {
+			return fmt.Errorf("one of the flags %s is required", flagList(c.Flags, ", "))
+		}
+		if len(given) > 1 {
+			return fmt.Errorf("flags %s cannot be used together", flagList(given, " and "))
+		}
+	case allOrNone:
+		if len(given) > 0 && len(missing) > 0 {
+			return fmt.Errorf("flags %s must be used together, missing %s",
+				flagList(c.Flags, ", "), flagList(missing, ", "))
+		}
+	case dependsOn:
+		if len(given) == 0 {
+			return nil
+		}
+		for _, dep := range c.Deps {
+			if !set[dep] {
+				missing = append(missing, dep)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("flag %s requires %s", flagList(c.Flags, ""), flagList(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// String describes the constraint, such as "at most one of -json, -yaml".
+func (c Constraint) String() string {
+	switch c.kind {
+	case required:
+		return "required: " + flagList(c.Flags, ", ")
+	case mutuallyExclusive:
+		return "at most one of " + flagList(c.Flags, ", ")
+	case exactlyOne:
+		return "exactly one of " + flagList(c.Flags, ", ")
+	case allOrNone:
+		return "all or none of " + flagList(c.Flags, ", ")
+	case dependsOn:
+		return flagList(c.Flags, "") + " requires " + flagList(c.Deps, ", ")
+	}
+	return ""
+}
+
+// checkConstraints returns the first constraint of cmd not satisfied by
+// the flags set in fs.
+func checkConstraints(cmd Command, fs *flag.FlagSet) error {
+	cc, ok := cmd.(ConstrainedCommand)
+	if !ok {
+		return nil
+	}
+	for _, c := range cc.Constraints() {
+		if err := c.Check(fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requiredFlags returns the names of the flags required by cmd.
+func requiredFlags(cmd Command) map[string]bool {
+	res := make(map[string]bool)
+	if cc, ok := cmd.(ConstrainedCommand); ok {
+		for _, c := range cc.Constraints() {
+			if c.kind == required {
+				for _, name := range c.Flags {
+					res[name] = true
+				}
+			}
+		}
+	}
+	return res
+}
+
+// annotateRequired returns an annotator marking the flags required by
+// cmd, followed by the result of annotate when not nil.
+func annotateRequired(cmd Command, annotate func(*flag.Flag) string) func(*flag.Flag) string {
+	req := requiredFlags(cmd)
+	if len(req) == 0 {
+		return annotate
+	}
+	return func(f *flag.Flag) string {
+		var note string
+		if annotate != nil {
+			note = annotate(f)
+		}
+		if req[f.Name] {
+			note = strings.TrimSpace("(required) " + note)
+		}
+		return note
+	}
+}
+
+// constraintLines describes the constraints of cmd, except the
+// required flags, which are marked in the list of flags.
+func constraintLines(cmd Command) []string {
+	cc, ok := cmd.(ConstrainedCommand)
+	if !ok {
+		return nil
+	}
+	var lines []string
+	for _, c := range cc.Constraints() {
+		if c.kind != required {
+			lines = append(lines, c.String())
+		}
+	}
+	return lines
+}
+
+// flagList returns the names as flags joined by sep, such as "-a, -b".
+func flagList(names []string, sep string) string {
+	res := make([]string, len(names))
+	for i, name := range names {
+		res[i] = "-" + name
+	}
+	return strings.Join(res, sep)
+}
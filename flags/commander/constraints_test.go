@@ -0,0 +1,114 @@
+package commander_test
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type deployCmd struct {
+	constraints []commander.Constraint
+	ran         bool
+}
+
+func (c *deployCmd) Name() string     { return "deploy" }
+func (c *deployCmd) Synopsis() string { return "Deploy the application." }
+func (c *deployCmd) Usage() string    { return "deploy [flags]" }
+
+func (c *deployCmd) SetFlags(f *flag.FlagSet) {
+	f.String("env", "", "target environment")
+	f.Bool("json", false, "JSON output")
+	f.Bool("yaml", false, "YAML output")
+	f.String("user", "", "user name")
+	f.String("password", "", "password")
+	f.String("cert", "", "client certificate")
+	f.String("key", "", "client key")
+	commander.Shorthand(f, "env", 'e')
+	commander.Shorthand(f, "json", 'j')
+}
+
+func (c *deployCmd) Execute(f *flag.FlagSet) error {
+	c.ran = true
+	return nil
+}
+
+func (c *deployCmd) Constraints() []commander.Constraint { return c.constraints }
+
+func TestConstraints(t *testing.T) {
+	tests := []struct {
+		constraint commander.Constraint
+		args       []string
+		err        string
+	}{
+		{commander.Required("env"), []string{"-env", "prod"}, ""},
+		{commander.Required("env"), []string{"-e", "prod"}, ""},
+		{commander.Required("env", "user"), nil, "required flags -env, -user not set"},
+		{commander.MutuallyExclusive("json", "yaml"), []string{"-json"}, ""},
+		{commander.MutuallyExclusive("json", "yaml"), []string{"-json", "-yaml"}, "flags -json and -yaml cannot be used together"},
+		{commander.MutuallyExclusive("json", "yaml"), []string{"-j", "-yaml"}, "flags -json and -yaml cannot be used together"},
+		{commander.ExactlyOne("json", "yaml"), []string{"-j"}, ""},
+		{commander.ExactlyOne("json", "yaml"), nil, "one of the flags -json, -yaml is required"},
+		{commander.AllOrNone("cert", "key"), nil, ""},
+		{commander.AllOrNone("cert", "key"), []string{"-cert", "c"}, "flags -cert, -key must be used together, missing -key"},
+		{commander.DependsOn("password", "user"), []string{"-user", "u"}, ""},
+		{commander.DependsOn("password", "user"), []string{"-password", "p"}, "flag -password requires -user"},
+	}
+	for _, tt := range tests {
+		cmd := &deployCmd{constraints: []commander.Constraint{tt.constraint}}
+		cdr, _ := newTestCommander(append([]string{"deploy"}, tt.args...)...)
+		cdr.Register(cmd, "")
+
+		err := cdr.Execute()
+		if tt.err == "" {
+			if err != nil || !cmd.ran {
+				t.Errorf("%v %v: want the command run, got %v", tt.constraint, tt.args, err)
+			}
+			continue
+		}
+		if !errors.Is(err, commander.ErrInvalidFlags) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%v %v: want error %q, got %v", tt.constraint, tt.args, tt.err, err)
+		}
+		if cmd.ran {
+			t.Errorf("%v %v: want the command not run", tt.constraint, tt.args)
+		}
+	}
+}
+
+func TestConstraintsFromEnv(t *testing.T) {
+	t.Setenv("TOOL_DEPLOY_ENV", "prod")
+
+	cmd := &deployCmd{constraints: []commander.Constraint{commander.Required("env")}}
+	cdr, _ := newTestCommander("deploy")
+	cdr.EnvPrefix = "TOOL"
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHelpConstraints(t *testing.T) {
+	cmd := &deployCmd{constraints: []commander.Constraint{
+		commander.Required("env"),
+		commander.MutuallyExclusive("json", "yaml"),
+		commander.DependsOn("password", "user"),
+	}}
+	cdr, out := newTestCommander("help", "deploy")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"target environment (required)",
+		"Constraints:\n   at most one of -json, -yaml\n   -password requires -user\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in the help, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package commander
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// ExitInterrupted is the exit code used when the program is forced to
+// exit by a second interrupt or by the expiry of the grace period.
+const ExitInterrupted = 130
+
+// A ContextCommand is a Command that receives a context. When the
+// command is executed through ExecuteContext, the context is cancelled
+// on SIGINT or SIGTERM. Its Run method is called in place of Execute.
+type ContextCommand interface {
+	Command
+
+	// Run executes the command.
+	Run(ctx context.Context, f *flag.FlagSet) error
+}
+
+// ExecuteContext is like Execute, but ContextCommands are run with a
+// context derived from ctx that is cancelled on the first SIGINT or
+// SIGTERM. The command is then given GracePeriod to return; the program
+// exits with ExitInterrupted if it does not, or if a second signal
+// arrives in the meantime. Commands which are not ContextCommands are
+// executed unchanged.
+func (cdr *Commander) ExecuteContext(ctx context.Context) error {
+	return cdr.execute(context.WithValue(ctx, signalsKey{}, true))
+}
+
+// A signalsKey is the context key marking the executions that should
+// cancel the context of ContextCommands on SIGINT or SIGTERM.
+type signalsKey struct{}
+
+// invokeWithSignals invokes inv like invoke, within withSignals when
+// the command is a ContextCommand and ctx asks for it. The signals are
+// left alone otherwise, so that they stop plain commands as usual.
+func (cdr *Commander) invokeWithSignals(ctx context.Context, inv *Invocation) error {
+	if _, ok := inv.Command.(ContextCommand); !ok || ctx.Value(signalsKey{}) == nil {
+		return cdr.invoke(ctx, inv)
+	}
+	return cdr.withSignals(ctx, func(ctx context.Context) error {
+		return cdr.invoke(ctx, inv)
+	})
+}
+
+// A stdioKey is the context key of the Commander executing a command.
+type stdioKey struct{}
+
+// Stdio returns the Input, Output and Error of the Commander executing
+// the ContextCommand given ctx, or the standard streams of the process
+// when ctx does not come from a Commander. Commands printing through
+// these writers rather than os.Stdout can be tested in-process (see the
+// commandertest package).
+func Stdio(ctx context.Context) (in io.Reader, out, errOut io.Writer) {
+	if cdr, ok := ctx.Value(stdioKey{}).(*Commander); ok {
+		return cdr.Input, cdr.Output, cdr.Error
+	}
+	return os.Stdin, os.Stdout, os.Stderr
+}
+
+// withSignals calls fn with a context derived from ctx, cancelled on
+// the first SIGINT or SIGTERM received before fn returns.
+func (cdr *Commander) withSignals(ctx context.Context, fn func(context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sigc := make(chan os.Signal, 2)
+	signal.Notify(sigc, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	done := make(chan struct{})
+	defer close(done)
+	go cdr.watchSignals(sigc, cancel, done)
+
+	return fn(ctx)
+}
+
+// watchSignals cancels the command context on the first signal and
+// exits the program on the second one, or when the grace period
+// expires, unless done is closed first.
+func (cdr *Commander) watchSignals(sigc <-chan os.Signal, cancel context.CancelFunc, done <-chan struct{}) {
+	select {
+	case <-done:
+		return
+	case sig := <-sigc:
+		fmt.Fprintf(cdr.Error, "\nReceived %v, shutting down (repeat to force exit)\n", sig)
+		cancel()
+	}
+
+	var timeout <-chan time.Time
+	if cdr.GracePeriod > 0 {
+		timer := time.NewTimer(cdr.GracePeriod)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-done:
+		return
+	case sig := <-sigc:
+		fmt.Fprintf(cdr.Error, "Received %v, exiting\n", sig)
+	case <-timeout:
+		fmt.Fprintf(cdr.Error, "Grace period of %v expired, exiting\n", cdr.GracePeriod)
+	}
+	cdr.exit(ExitInterrupted)
+}
@@ -0,0 +1,107 @@
+package commander_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+type ctxCmd struct {
+	testCmd
+	started chan struct{}
+}
+
+func (c *ctxCmd) Run(ctx context.Context, f *flag.FlagSet) error {
+	close(c.started)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestExecuteContextCancel(t *testing.T) {
+	cmd := &ctxCmd{testCmd: testCmd{name: "wait"}, started: make(chan struct{})}
+	cdr, _ := newTestCommander("wait")
+	cdr.Register(cmd, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-cmd.started
+		cancel()
+	}()
+
+	if err := cdr.ExecuteContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+	if cmd.ran {
+		t.Fatal("want Run called in place of Execute")
+	}
+}
+
+func TestExecuteContextSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send os.Interrupt on windows")
+	}
+
+	cmd := &ctxCmd{testCmd: testCmd{name: "wait"}, started: make(chan struct{})}
+	cdr, _ := newTestCommander("wait")
+	cdr.Register(cmd, "")
+	cdr.GracePeriod = time.Minute
+
+	go func() {
+		<-cmd.started
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(os.Interrupt)
+	}()
+
+	if err := cdr.ExecuteContext(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled, got %v", err)
+	}
+}
+
+func TestExecutePlainCommand(t *testing.T) {
+	cmd := &testCmd{name: "run"}
+	cdr, _ := newTestCommander("run")
+	cdr.Register(cmd, "")
+
+	if err := cdr.ExecuteContext(context.Background()); err != nil || !cmd.ran {
+		t.Fatalf("want plain command executed, got %v", err)
+	}
+}
+
+type signalCmd struct {
+	testCmd
+	sigc chan os.Signal
+}
+
+func (c *signalCmd) Execute(f *flag.FlagSet) error {
+	p, _ := os.FindProcess(os.Getpid())
+	p.Signal(os.Interrupt)
+	<-c.sigc
+	return nil
+}
+
+func TestExecuteContextPlainSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send os.Interrupt on windows")
+	}
+
+	// The test receives the signal in place of the default handler,
+	// which would stop the process.
+	cmd := &signalCmd{testCmd: testCmd{name: "run"}, sigc: make(chan os.Signal, 1)}
+	signal.Notify(cmd.sigc, os.Interrupt)
+	defer signal.Stop(cmd.sigc)
+
+	cdr, out := newTestCommander("run")
+	cdr.Register(cmd, "")
+	if err := cdr.ExecuteContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "shutting down") {
+		t.Fatalf("want the signal left alone for plain commands, got:\n%s", out.String())
+	}
+}
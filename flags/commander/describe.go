@@ -0,0 +1,199 @@
+package commander
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+)
+
+// A Description is a serialisable model of a Commander, as returned by
+// Describe. Hidden commands and plugins are omitted.
+type Description struct {
+	Name    string             `json:"name"`
+	Version string             `json:"version,omitempty"`
+	Banner  string             `json:"banner,omitempty"`
+	Flags   []FlagDescription  `json:"flags,omitempty"` // the top-level flags
+	Groups  []GroupDescription `json:"groups"`
+}
+
+// A GroupDescription describes a command group.
+type GroupDescription struct {
+	Name     string               `json:"name"`
+	Commands []CommandDescription `json:"commands"`
+}
+
+// A CommandDescription describes a command.
+type CommandDescription struct {
+	Name        string               `json:"name"`
+	Path        string               `json:"path"` // such as "tool remote add"
+	Synopsis    string               `json:"synopsis"`
+	Usage       string               `json:"usage"`
+	Aliases     []string             `json:"aliases,omitempty"`
+	Deprecated  string               `json:"deprecated,omitempty"`
+	Args        *ArgsDescription     `json:"args,omitempty"`
+	Flags       []FlagDescription    `json:"flags,omitempty"`
+	Constraints []string             `json:"constraints,omitempty"`
+	Subcommands []CommandDescription `json:"subcommands,omitempty"`
+}
+
+// An ArgsDescription describes the positional arguments of an
+// ArgsCommand. A negative Max means no limit.
+type ArgsDescription struct {
+	Min  int              `json:"min"`
+	Max  int              `json:"max"`
+	Args []ArgDescription `json:"args,omitempty"`
+}
+
+// An ArgDescription describes a named positional argument.
+type ArgDescription struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// A FlagDescription describes a flag.
+type FlagDescription struct {
+	Name       string   `json:"name"`
+	Short      string   `json:"short,omitempty"`
+	Type       string   `json:"type,omitempty"`
+	Default    string   `json:"default"`
+	Usage      string   `json:"usage"`
+	Choices    []string `json:"choices,omitempty"`
+	Required   bool     `json:"required,omitempty"`
+	Persistent bool     `json:"persistent,omitempty"`
+	Env        string   `json:"env,omitempty"`
+}
+
+// Describe returns a model of the commander: its name, version, banner
+// and top-level flags, and its command groups, descending into
+// Subcommanders.
+func (cdr *Commander) Describe() Description {
+	d := Description{
+		Name:    cdr.name,
+		Version: cdr.buildInfo().Version,
+		Banner:  cdr.Banner,
+		Groups:  []GroupDescription{},
+	}
+
+	persistent := make(map[string]bool)
+	for _, f := range cdr.persistentFlags() {
+		persistent[f.Name] = true
+	}
+	d.Flags = describeFlags(cdr.VisitAll, func(f *FlagDescription) {
+		f.Persistent = persistent[f.Name]
+		f.Env = cdr.envName(nil, f.Name)
+	})
+
+	cdr.VisitGroups(func(g *CommandGroup) {
+		gd := GroupDescription{Name: g.name, Commands: []CommandDescription{}}
+		for _, cmd := range g.commands {
+			if !isHidden(cmd) {
+				gd.Commands = append(gd.Commands, cdr.describeCommand(cmd, []string{cdr.name}))
+			}
+		}
+		if len(gd.Commands) > 0 {
+			d.Groups = append(d.Groups, gd)
+		}
+	})
+	return d
+}
+
+// describeCommand returns the description of cmd, whose parents are
+// named by path.
+func (cdr *Commander) describeCommand(cmd Command, path []string) CommandDescription {
+	path = append(path[:len(path):len(path)], cmd.Name())
+	cd := CommandDescription{
+		Name:     cmd.Name(),
+		Path:     strings.Join(path, " "),
+		Synopsis: cmd.Synopsis(),
+		Usage:    cmd.Usage(),
+		Aliases:  aliasesOf(cmd),
+	}
+	if d, ok := cmd.(Deprecator); ok {
+		cd.Deprecated = d.Deprecated()
+	}
+	if ac, ok := cmd.(ArgsCommand); ok {
+		spec := ac.Args()
+		cd.Args = &ArgsDescription{Min: spec.Min, Max: spec.max()}
+		for _, a := range spec.Args {
+			cd.Args.Args = append(cd.Args.Args, ArgDescription{Name: a.Name, Description: a.Description})
+		}
+	}
+	cd.Constraints = constraintLines(cmd)
+
+	fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cmd.SetFlags(fs)
+	req := requiredFlags(cmd)
+	cd.Flags = describeFlags(fs.VisitAll, func(f *FlagDescription) {
+		f.Required = req[f.Name]
+		f.Env = cdr.envName(path[1:], f.Name)
+	})
+
+	if sc, ok := cmd.(Subcommander); ok {
+		for _, child := range sc.Subcommands() {
+			if !isHidden(child) {
+				cd.Subcommands = append(cd.Subcommands, cdr.describeCommand(child, path))
+			}
+		}
+	}
+	return cd
+}
+
+// describeFlags returns the descriptions of the visited flags, completed
+// by fn.
+func describeFlags(visit func(func(*flag.Flag)), fn func(*FlagDescription)) []FlagDescription {
+	var res []FlagDescription
+	shorts := shorthands(visit)
+	visit(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+		typ, usage := unquoteUsage(f)
+		fd := FlagDescription{
+			Name:    f.Name,
+			Short:   shorts[f.Name],
+			Type:    typ,
+			Default: f.DefValue,
+			Usage:   usage,
+			Choices: choicesOf(f.Value),
+		}
+		fn(&fd)
+		res = append(res, fd)
+	})
+	return res
+}
+
+// describeArg is the name of the hidden command printing the description
+// of the commander.
+const describeArg = "__describe"
+
+// A describer is a Command implementing a hidden "__describe" command
+// for a given Commander.
+type describer Commander
+
+func (d *describer) Name() string { return describeArg }
+func (d *describer) Synopsis() string {
+	return "Print a JSON description of the commands and flags."
+}
+func (d *describer) SetFlags(*flag.FlagSet) {}
+func (d *describer) Usage() string {
+	return describeArg + `
+
+Prints a JSON description of the program, its commands and their
+flags, for documentation tools and compatibility tests.
+`
+}
+func (d *describer) Hidden() bool  { return true }
+func (d *describer) Args() ArgSpec { return NoArgs() }
+func (d *describer) Execute(f *flag.FlagSet) error {
+	enc := json.NewEncoder(d.Output)
+	enc.SetIndent("", "  ")
+	return enc.Encode((*Commander)(d).Describe())
+}
+
+// DescribeCommand returns a hidden Command which implements a
+// "__describe" subcommand, printing the result of Describe as JSON.
+func (cdr *Commander) DescribeCommand() Command {
+	return (*describer)(cdr)
+}
@@ -0,0 +1,76 @@
+package commander_test
+
+import (
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type formatCmd struct {
+	testCmd
+	format flags.Enum
+}
+
+func (c *formatCmd) SetFlags(f *flag.FlagSet) {
+	c.format = flags.Enum{Choices: []string{"json", "yaml"}}
+	f.Var(&c.format, "format", "output `format`")
+	commander.Shorthand(f, "format", 'f')
+}
+
+func (c *formatCmd) Constraints() []commander.Constraint {
+	return []commander.Constraint{commander.Required("format")}
+}
+
+func (c *formatCmd) Args() commander.ArgSpec {
+	return commander.ExactArgs(1, commander.Arg{Name: "file", Description: "the input file"})
+}
+
+func TestDescribe(t *testing.T) {
+	add := &testCmd{name: "add"}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add}}}
+
+	cdr, out := newTestCommander("__describe")
+	cdr.Banner = "A test tool."
+	cdr.BuildInfo = &commander.BuildInfo{Version: "v1.2.3"}
+	cdr.Register(cdr.DescribeCommand(), "")
+	cdr.Register(remote, "")
+	cdr.Register(&formatCmd{testCmd: testCmd{name: "convert"}}, "data")
+	cdr.Register(&argsCmd{testCmd: testCmd{name: "cat"}, spec: commander.ArgSpec{Min: 1}}, "data")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var d commander.Description
+	if err := json.Unmarshal(out.Bytes(), &d); err != nil {
+		t.Fatalf("%v:\n%s", err, out.String())
+	}
+	if d.Name != "tool" || d.Version != "v1.2.3" || d.Banner != "A test tool." || len(d.Groups) != 2 {
+		t.Fatalf("unexpected description %+v", d)
+	}
+
+	cmds := d.Groups[0].Commands
+	if len(cmds) != 1 || cmds[0].Name != "remote" {
+		t.Fatalf("want the hidden command omitted, got %+v", cmds)
+	}
+	if sub := cmds[0].Subcommands; len(sub) != 1 || sub[0].Path != "tool remote add" {
+		t.Fatalf("want the nested command described, got %+v", sub)
+	}
+
+	convert, cat := d.Groups[1].Commands[0], d.Groups[1].Commands[1]
+	if cat.Args == nil || cat.Args.Min != 1 || cat.Args.Max != -1 {
+		t.Fatalf("want no limit described for a zero Max, got %+v", cat.Args)
+	}
+	if convert.Args == nil || convert.Args.Min != 1 || convert.Args.Args[0].Name != "file" {
+		t.Fatalf("want the args described, got %+v", convert.Args)
+	}
+	f := convert.Flags[0]
+	if f.Name != "format" || f.Short != "f" || f.Type != "format" || !f.Required ||
+		strings.Join(f.Choices, ",") != "json,yaml" {
+		t.Fatalf("unexpected flag description %+v", f)
+	}
+}
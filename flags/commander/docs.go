@@ -0,0 +1,297 @@
+package commander
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A docCommand is a command along with what is needed to document it.
+type docCommand struct {
+	path  []string // full command path, starting with the program name
+	group string
+	cmd   Command
+	flags *flag.FlagSet
+}
+
+// docCommands returns all the commands to document, descending into
+// Subcommanders, in the order of VisitCommands. Hidden commands are
+// omitted.
+func (cdr *Commander) docCommands() []docCommand {
+	var res []docCommand
+	var walk func(group string, path []string, cmds []Command)
+	walk = func(group string, path []string, cmds []Command) {
+		for _, cmd := range cmds {
+			if isHidden(cmd) {
+				continue
+			}
+			fs := flag.NewFlagSet(cmd.Name(), flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			cmd.SetFlags(fs)
+			p := append(path[:len(path):len(path)], cmd.Name())
+			res = append(res, docCommand{path: p, group: group, cmd: cmd, flags: fs})
+			if sc, ok := cmd.(Subcommander); ok {
+				walk(group, p, sc.Subcommands())
+			}
+		}
+	}
+
+	cdr.VisitGroups(func(g *CommandGroup) {
+		walk(g.name, []string{cdr.name}, g.commands)
+	})
+	return res
+}
+
+// GenMarkdown writes to w a Markdown reference of the commander: the
+// banner, the top-level flags and, grouped by command group, the
+// synopsis, usage and flags of every command.
+func (cdr *Commander) GenMarkdown(w io.Writer) error {
+	ew := &errWriter{w: w}
+
+	fmt.Fprintf(ew, "# %s\n\n", cdr.name)
+	if cdr.Banner != "" {
+		fmt.Fprintf(ew, "%s\n\n", cdr.Banner)
+	}
+	fmt.Fprintf(ew, "```\n%s [flags] <command> [args]\n```\n\n", cdr.name)
+
+	if cdr.countTopFlags() > 0 {
+		fmt.Fprintf(ew, "## Flags\n\n")
+		writeMarkdownFlags(ew, cdr.VisitAll)
+	}
+
+	group := "\x00"
+	for _, dc := range cdr.docCommands() {
+		if dc.group != group {
+			group = dc.group
+			if group == "" {
+				fmt.Fprintf(ew, "## Commands\n\n")
+			} else {
+				fmt.Fprintf(ew, "## Commands for %s\n\n", group)
+			}
+		}
+
+		fmt.Fprintf(ew, "### %s\n\n", strings.Join(dc.path, " "))
+		fmt.Fprintf(ew, "%s\n\n", dc.cmd.Synopsis())
+		fmt.Fprintf(ew, "```\n%s\n```\n\n", strings.TrimSpace(dc.cmd.Usage()))
+		if countFlags(dc.flags) > 0 {
+			writeMarkdownFlags(ew, dc.flags.VisitAll)
+		}
+	}
+	return ew.err
+}
+
+// writeMarkdownFlags writes a Markdown table of the flags visited by visit.
+func writeMarkdownFlags(w io.Writer, visit func(func(*flag.Flag))) {
+	fmt.Fprintf(w, "| Flag | Type | Default | Description |\n")
+	fmt.Fprintf(w, "| ---- | ---- | ------- | ----------- |\n")
+	shorts := shorthands(visit)
+	visit(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+		typ, desc := unquoteUsage(f)
+		fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n",
+			flagLabel(f, shorts), markdownCell(typ), markdownCell(defaultOf(f)), markdownCell(desc))
+	})
+	fmt.Fprintln(w)
+}
+
+func markdownCell(s string) string {
+	if s == "" {
+		return ""
+	}
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// GenManPages writes roff man pages in section 1 to dir: an index page
+// named after the commander, listing the top-level flags and the
+// commands, and one page per command named after the command path, such
+// as "tool-remote-add.1".
+func (cdr *Commander) GenManPages(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	date := time.Now().Format("January 2006")
+	cmds := cdr.docCommands()
+
+	write := func(name string, fn func(io.Writer)) error {
+		file, err := os.Create(filepath.Join(dir, name+".1"))
+		if err != nil {
+			return err
+		}
+		ew := &errWriter{w: file}
+		fn(ew)
+		if err := file.Close(); ew.err == nil {
+			ew.err = err
+		}
+		return ew.err
+	}
+
+	err := write(cdr.name, func(w io.Writer) {
+		manHeader(w, cdr.name, date, cdr.name)
+		fmt.Fprintf(w, ".SH NAME\n%s\n", roff(cdr.name))
+		fmt.Fprintf(w, ".SH SYNOPSIS\n.B %s\n[\\fIflags\\fR] \\fIcommand\\fR [\\fIargs\\fR]\n", roff(cdr.name))
+		if cdr.Banner != "" {
+			fmt.Fprintf(w, ".SH DESCRIPTION\n%s\n", roff(cdr.Banner))
+		}
+		if cdr.countTopFlags() > 0 {
+			fmt.Fprintf(w, ".SH OPTIONS\n")
+			writeManFlags(w, cdr.VisitAll)
+		}
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, dc := range cmds {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", roff(strings.Join(dc.path[1:], " ")), roff(dc.cmd.Synopsis()))
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, dc := range cmds {
+		dc := dc
+		name := strings.Join(dc.path, "-")
+		err := write(name, func(w io.Writer) {
+			manHeader(w, name, date, cdr.name)
+			fmt.Fprintf(w, ".SH NAME\n%s \\- %s\n", roff(name), roff(dc.cmd.Synopsis()))
+			fmt.Fprintf(w, ".SH SYNOPSIS\n.nf\n%s\n.fi\n", roff(strings.TrimSpace(dc.cmd.Usage())))
+			if countFlags(dc.flags) > 0 {
+				fmt.Fprintf(w, ".SH OPTIONS\n")
+				writeManFlags(w, dc.flags.VisitAll)
+			}
+			fmt.Fprintf(w, ".SH SEE ALSO\n.BR %s (1)\n", roff(cdr.name))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func manHeader(w io.Writer, name, date, source string) {
+	fmt.Fprintf(w, ".TH \"%s\" \"1\" \"%s\" \"%s\" \"%s Manual\"\n",
+		roff(strings.ToUpper(name)), date, roff(source), roff(source))
+}
+
+// writeManFlags writes a roff tagged paragraph for each flag visited by visit.
+func writeManFlags(w io.Writer, visit func(func(*flag.Flag))) {
+	shorts := shorthands(visit)
+	visit(func(f *flag.Flag) {
+		if isShorthand(f) {
+			return
+		}
+		typ, desc := unquoteUsage(f)
+		fmt.Fprintf(w, ".TP\n.B %s", strings.ReplaceAll(roff(flagLabel(f, shorts)), "-", "\\-"))
+		if typ != "" {
+			fmt.Fprintf(w, " \\fI%s\\fR", roff(typ))
+		}
+		fmt.Fprintf(w, "\n%s", roff(desc))
+		if def := defaultOf(f); def != "" {
+			fmt.Fprintf(w, " (default: %s)", roff(def))
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// roff escapes s for inclusion in a roff document.
+func roff(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// defaultOf returns the default value of a flag worth documenting, or
+// the empty string for zero values.
+func defaultOf(f *flag.Flag) string {
+	switch f.DefValue {
+	case "", "0", "false", "[]", "0s":
+		return ""
+	}
+	return f.DefValue
+}
+
+// An errWriter is an io.Writer remembering the first write error.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) Write(p []byte) (int, error) {
+	if ew.err != nil {
+		return 0, ew.err
+	}
+	var n int
+	n, ew.err = ew.w.Write(p)
+	return n, ew.err
+}
+
+// A gendocs is a Command implementing a hidden "gendocs" command for
+// a given Commander.
+type gendocs struct {
+	cdr    *Commander
+	format string
+	out    string
+}
+
+func (g *gendocs) Name() string { return "gendocs" }
+func (g *gendocs) Synopsis() string {
+	return "Generate the reference documentation."
+}
+func (g *gendocs) Hidden() bool { return true }
+func (g *gendocs) SetFlags(f *flag.FlagSet) {
+	f.StringVar(&g.format, "format", "markdown", "output `format`: markdown or man")
+	f.StringVar(&g.out, "out", "", "output `dir`ectory (default: standard output for markdown, current directory for man)")
+}
+func (g *gendocs) Usage() string {
+	return `gendocs [-format markdown|man] [-out <dir>]
+
+Generates the reference documentation of all the commands, either as
+a single Markdown document or as one man page per command.
+`
+}
+func (g *gendocs) Execute(f *flag.FlagSet) error {
+	switch g.format {
+	case "markdown":
+		if g.out == "" {
+			return g.cdr.GenMarkdown(g.cdr.Output)
+		}
+		if err := os.MkdirAll(g.out, 0o755); err != nil {
+			return err
+		}
+		file, err := os.Create(filepath.Join(g.out, g.cdr.name+".md"))
+		if err != nil {
+			return err
+		}
+		if err := g.cdr.GenMarkdown(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+
+	case "man":
+		if g.out == "" {
+			g.out = "."
+		}
+		return g.cdr.GenManPages(g.out)
+	}
+
+	fmt.Fprintf(g.cdr.Error, "Format %s not supported\n", g.format)
+	f.Usage()
+	return &UsageError{Kind: ErrUsage, Command: g.Name()}
+}
+
+// GenDocsCommand returns a hidden Command which implements a "gendocs"
+// subcommand, writing the output of GenMarkdown or GenManPages.
+func (cdr *Commander) GenDocsCommand() Command {
+	return &gendocs{cdr: cdr}
+}
@@ -0,0 +1,93 @@
+package commander_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+// A docsCmd is a command with a flag naming its argument type.
+type docsCmd struct {
+	testCmd
+	timeout time.Duration
+}
+
+func (c *docsCmd) SetFlags(f *flag.FlagSet) {
+	f.DurationVar(&c.timeout, "timeout", time.Second, "`duration` request timeout")
+}
+
+func newDocsCommander() *commander.Commander {
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{
+		&docsCmd{testCmd: testCmd{name: "add"}},
+	}}}
+
+	cdr, _ := newTestCommander()
+	cdr.Banner = "A tool for tests."
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cdr.GenDocsCommand(), "")
+	cdr.Register(remote, "remotes")
+	return cdr
+}
+
+func TestGenMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newDocsCommander().GenMarkdown(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	doc := buf.String()
+	for _, want := range []string{
+		"# tool\n",
+		"A tool for tests.",
+		"## Commands for remotes",
+		"### tool remote add",
+		"| `-timeout` | duration | 1s | request timeout |",
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("want %q in:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "gendocs") {
+		t.Errorf("want hidden gendocs command omitted, got:\n%s", doc)
+	}
+}
+
+func TestGenManPages(t *testing.T) {
+	dir := t.TempDir()
+	if err := newDocsCommander().GenManPages(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"tool.1", "tool-help.1", "tool-remote.1", "tool-remote-add.1"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("want man page %s: %v", name, err)
+		}
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "tool-remote-add.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(page), ".B \\-timeout \\fIduration\\fR") {
+		t.Errorf("want timeout flag documented, got:\n%s", page)
+	}
+}
+
+func TestGenDocsHidden(t *testing.T) {
+	cdr, out := newTestCommander("help")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cdr.GenDocsCommand(), "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "gendocs") {
+		t.Fatalf("want gendocs hidden from help, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,104 @@
+package commander
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+)
+
+// Exit codes returned by ExitCode.
+const (
+	ExitSuccess    = 0
+	ExitFailure    = 1
+	ExitUsageError = 2
+)
+
+var (
+	// ErrUsage is reported when the command line is malformed. Every
+	// UsageError matches it with errors.Is.
+	ErrUsage = errors.New("usage error")
+
+	// ErrUnknownCommand is reported when the command line names a
+	// command that is not registered.
+	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrFlagParse is reported when the flags of a command cannot be
+	// parsed. The UsageError wraps the error returned by the flag package.
+	ErrFlagParse = errors.New("flag parse error")
+
+	// ErrInvalidArgs is reported when the positional arguments of a
+	// command do not satisfy its ArgSpec.
+	ErrInvalidArgs = errors.New("invalid arguments")
+
+	// ErrInvalidFlags is reported when the flags of a command do not
+	// satisfy its constraints.
+	ErrInvalidFlags = errors.New("invalid flags")
+
+	errNoCommand = errors.New("no command specified")
+)
+
+// A UsageError is returned by Commander.Execute when the command line
+// cannot be dispatched to a command.
+type UsageError struct {
+	Kind    error  // ErrUsage, ErrUnknownCommand, ErrFlagParse, ErrInvalidArgs or ErrInvalidFlags
+	Command string // the command involved, if any
+	Err     error  // the underlying error, if any
+
+	// Suggestions holds the closest matches to a misspelled command
+	// or flag name.
+	Suggestions []string
+}
+
+func (e *UsageError) Error() string {
+	var msg string
+	switch {
+	case e.Err != nil && e.Command != "":
+		msg = fmt.Sprintf("%s: %v", e.Command, e.Err)
+	case e.Err != nil:
+		msg = e.Err.Error()
+	case e.Command != "":
+		msg = fmt.Sprintf("%v %q", e.Kind, e.Command)
+	default:
+		msg = e.Kind.Error()
+	}
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestions[0])
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error.
+func (e *UsageError) Unwrap() error { return e.Err }
+
+// Is reports whether target is the kind of this error or ErrUsage.
+func (e *UsageError) Is(target error) bool {
+	return target == ErrUsage || target == e.Kind
+}
+
+// An ExitCoder is an error carrying its own exit code. Commands can
+// return one from Execute to control the exit status of the program.
+type ExitCoder interface {
+	error
+	ExitCode() int
+}
+
+// ExitCode returns the process exit code for an error returned by
+// Commander.Execute: ExitSuccess for nil or a help request, the code of
+// an ExitCoder, ExitUsageError for usage errors and ExitFailure
+// otherwise. It lets main do
+//
+//	os.Exit(commander.ExitCode(app.Execute()))
+func ExitCode(err error) int {
+	if err == nil || errors.Is(err, flag.ErrHelp) {
+		return ExitSuccess
+	}
+
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	if errors.Is(err, ErrUsage) {
+		return ExitUsageError
+	}
+	return ExitFailure
+}
@@ -0,0 +1,98 @@
+package commander_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type exitErr int
+
+func (e exitErr) Error() string { return fmt.Sprintf("exit %d", int(e)) }
+func (e exitErr) ExitCode() int { return int(e) }
+
+type failCmd struct {
+	testCmd
+	err error
+}
+
+func (c *failCmd) Execute(*flag.FlagSet) error { return c.err }
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		args []string
+		kind error
+		code int
+	}{
+		{nil, commander.ErrUsage, commander.ExitUsageError},
+		{[]string{"stauts"}, commander.ErrUnknownCommand, commander.ExitUsageError},
+		{[]string{"run", "-nope"}, commander.ErrFlagParse, commander.ExitUsageError},
+		{[]string{"run", "-h"}, flag.ErrHelp, commander.ExitSuccess},
+		{[]string{"help", "stauts"}, commander.ErrUnknownCommand, commander.ExitUsageError},
+	}
+	for _, tt := range tests {
+		cdr, _ := newTestCommander(tt.args...)
+		cdr.Register(cdr.HelpCommand(), "")
+		cdr.Register(&testCmd{name: "run"}, "")
+
+		err := cdr.Execute()
+		if !errors.Is(err, tt.kind) {
+			t.Errorf("%q: want %v, got %v", tt.args, tt.kind, err)
+		}
+		if !errors.Is(err, commander.ErrUsage) && tt.kind != flag.ErrHelp {
+			t.Errorf("%q: want a usage error, got %v", tt.args, err)
+		}
+		if got := commander.ExitCode(err); got != tt.code {
+			t.Errorf("%q: want exit code %d, got %d", tt.args, tt.code, got)
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, commander.ExitSuccess},
+		{errors.New("boom"), commander.ExitFailure},
+		{exitErr(3), 3},
+		{fmt.Errorf("wrapped: %w", exitErr(4)), 4},
+	}
+	for _, tt := range tests {
+		cdr, _ := newTestCommander("fail")
+		cdr.Register(&failCmd{testCmd: testCmd{name: "fail"}, err: tt.err}, "")
+
+		if got := commander.ExitCode(cdr.Execute()); got != tt.want {
+			t.Errorf("%v: want exit code %d, got %d", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"stauts"}, "status"},
+		{[]string{"status", "-verbos"}, "-verbose"},
+		{[]string{"help", "stauts"}, "status"},
+	}
+	for _, tt := range tests {
+		cdr, out := newTestCommander(tt.args...)
+		cdr.Register(cdr.HelpCommand(), "")
+		cdr.Register(&testCmd{name: "status"}, "")
+
+		err := cdr.Execute()
+		var uerr *commander.UsageError
+		if !errors.As(err, &uerr) || len(uerr.Suggestions) == 0 || uerr.Suggestions[0] != tt.want {
+			t.Errorf("%q: want suggestion %q, got %v", tt.args, tt.want, err)
+		}
+		if !strings.Contains(out.String(), tt.want+`"?`) && !strings.Contains(out.String(), tt.want+"?") {
+			t.Errorf("%q: want suggestion printed, got:\n%s", tt.args, out.String())
+		}
+	}
+}
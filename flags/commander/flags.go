@@ -0,0 +1,140 @@
+package commander
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A flagInfo describes a top-level flag in the JSON output of the
+// flags command.
+type flagInfo struct {
+	Name    string `json:"name"`
+	Short   string `json:"short,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Default string `json:"default"`
+	Value   string `json:"value"`
+	Usage   string `json:"usage"`
+	Env     string `json:"env,omitempty"`
+}
+
+// A flagsCmd is a Command implementing a "flags" command for
+// a given Commander.
+type flagsCmd struct {
+	cdr  *Commander
+	json bool
+}
+
+func (c *flagsCmd) Name() string { return "flags" }
+func (c *flagsCmd) Synopsis() string {
+	return "Describe all known top-level flags."
+}
+func (c *flagsCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.json, "json", false, "print the flags as JSON")
+}
+func (c *flagsCmd) Usage() string {
+	return `flags [-json] [<flag>...]
+
+With arguments, prints the top-level flags whose names start with
+any of the arguments. With no argument, prints all top-level flags
+along with their type, default value and usage.
+`
+}
+func (c *flagsCmd) Execute(f *flag.FlagSet) error {
+	matches, err := c.cdr.matchTopFlags(f.Args())
+	if err != nil {
+		fmt.Fprintln(c.cdr.Error, err)
+		return err
+	}
+	if c.json {
+		return c.cdr.writeFlagsJSON(c.cdr.Output, matches)
+	}
+	c.cdr.writeFlags(c.cdr.Output, matches)
+	return nil
+}
+
+// FlagsCommand returns a Command which implements a "flags" subcommand,
+// listing all the top-level flags.
+func (cdr *Commander) FlagsCommand() Command {
+	return &flagsCmd{cdr: cdr}
+}
+
+// matchTopFlags returns the top-level flags whose names start with any
+// of the given prefixes, or all of them when no prefix is given.
+func (cdr *Commander) matchTopFlags(prefixes []string) ([]*flag.Flag, error) {
+	var res, all []*flag.Flag
+	cdr.VisitAll(func(f *flag.Flag) {
+		all = append(all, f)
+		if len(prefixes) == 0 {
+			res = append(res, f)
+			return
+		}
+		for _, p := range prefixes {
+			if strings.HasPrefix(f.Name, strings.TrimLeft(p, "-")) {
+				res = append(res, f)
+				return
+			}
+		}
+	})
+
+	if len(res) == 0 && len(prefixes) > 0 {
+		names := make([]string, 0, len(all))
+		for _, f := range all {
+			names = append(names, f.Name)
+		}
+		name := strings.TrimLeft(prefixes[0], "-")
+		return nil, &UsageError{
+			Kind:        ErrUsage,
+			Err:         fmt.Errorf("no top-level flag matches %s", strings.Join(prefixes, " ")),
+			Suggestions: suggestFlagNames(name, names),
+		}
+	}
+	return res, nil
+}
+
+// writeFlags prints the given top-level flags with their type, default
+// value and usage.
+func (cdr *Commander) writeFlags(w io.Writer, flags []*flag.Flag) {
+	if len(flags) == 0 {
+		fmt.Fprintln(w, "No top level flags.")
+		return
+	}
+
+	annotate := withDefaults(cdr.annotator(nil, nil))
+	shorts := shorthands(cdr.VisitAll)
+	var rows []HelpRow
+	for _, f := range flags {
+		if !isShorthand(f) {
+			rows = append(rows, flagRow(f, shorts, annotate))
+		}
+	}
+	fmt.Fprint(w, "Top-level flags:\n")
+	io.WriteString(w, formatRows(rows, cdr.widthOf(w)))
+}
+
+// writeFlagsJSON prints the given top-level flags as a JSON array.
+func (cdr *Commander) writeFlagsJSON(w io.Writer, flags []*flag.Flag) error {
+	shorts := shorthands(cdr.VisitAll)
+	infos := make([]flagInfo, 0, len(flags))
+	for _, f := range flags {
+		if isShorthand(f) {
+			continue
+		}
+		typ, desc := unquoteUsage(f)
+		infos = append(infos, flagInfo{
+			Name:    f.Name,
+			Short:   shorts[f.Name],
+			Type:    typ,
+			Default: f.DefValue,
+			Value:   f.Value.String(),
+			Usage:   desc,
+			Env:     cdr.envName(nil, f.Name),
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(infos)
+}
@@ -0,0 +1,74 @@
+package commander_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+func newFlagsCommander(args ...string) (*commander.Commander, *bytes.Buffer) {
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	fs.Bool("verbose", false, "be verbose")
+	fs.Int("parallel", 4, "number of `workers`")
+	fs.String("profile", "", "write a CPU profile to `file`")
+	fs.Parse(args)
+
+	var out bytes.Buffer
+	cdr := commander.New(fs, "tool")
+	cdr.Output = &out
+	cdr.Error = &out
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cdr.FlagsCommand(), "")
+	return cdr, &out
+}
+
+func TestFlagsCommand(t *testing.T) {
+	cdr, out := newFlagsCommander("flags")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"-parallel workers", "(default 4)", "-profile file", "-verbose"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFlagsCommandFilter(t *testing.T) {
+	cdr, out := newFlagsCommander("flags", "-json", "p")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Default string `json:"default"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "parallel" || got[0].Type != "workers" || got[0].Default != "4" || got[1].Name != "profile" {
+		t.Fatalf("want parallel and profile flags, got %+v", got)
+	}
+
+	cdr, _ = newFlagsCommander("flags", "verbsoe")
+	if err := cdr.Execute(); !errors.Is(err, commander.ErrUsage) {
+		t.Fatalf("want usage error, got %v", err)
+	}
+}
+
+func TestHelpFlags(t *testing.T) {
+	cdr, out := newFlagsCommander("help", "flags")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Top-level flags:") || !strings.Contains(out.String(), "-verbose") {
+		t.Fatalf("want top-level flags, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,152 @@
+package commander
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Shorthand defines the one-letter flag short as an alias of the flag
+// name of fs, sharing its value, as in "-v" for "--verbose". The help
+// output then lists both forms, as in "-v, --verbose". Shorthands can be
+// combined, as in "-xvf archive", when the Commander parses GNUFlags.
+func Shorthand(fs *flag.FlagSet, name string, short rune) {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("Shorthand for an undefined flag (%s)", name))
+	}
+	fs.Var(&shorthand{Value: f.Value, name: name}, string(short), f.Usage)
+}
+
+// A shorthand is the value of a one-letter alias of a flag.
+type shorthand struct {
+	flag.Value
+	name string // the name of the aliased flag
+}
+
+func (s *shorthand) IsBoolFlag() bool {
+	bf, ok := s.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// isShorthand reports whether f is an alias defined by Shorthand.
+func isShorthand(f *flag.Flag) bool {
+	_, ok := f.Value.(*shorthand)
+	return ok
+}
+
+// setFlags returns the names of the flags of fs given on the command
+// line. A shorthand given counts as its aliased flag being given.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+		if s, ok := f.Value.(*shorthand); ok {
+			set[s.name] = true
+		}
+	})
+	return set
+}
+
+// shorthands returns the shorthands of the visited flags, by name of the
+// aliased flag.
+func shorthands(visit func(func(*flag.Flag))) map[string]string {
+	res := make(map[string]string)
+	visit(func(f *flag.Flag) {
+		if s, ok := f.Value.(*shorthand); ok {
+			res[s.name] = f.Name
+		}
+	})
+	return res
+}
+
+// flagLabel returns how the flag f is shown in the help output, given
+// the shorthands of its flag set: "-name" when there are none, and GNU
+// style, as in "-v, --verbose" or "--name", otherwise.
+func flagLabel(f *flag.Flag, shorts map[string]string) string {
+	if len(shorts) == 0 || len(f.Name) == 1 {
+		return "-" + f.Name
+	}
+	if short, ok := shorts[f.Name]; ok {
+		return "-" + short + ", --" + f.Name
+	}
+	return "--" + f.Name
+}
+
+// gnuArgs translates the GNU style flags of args, as in "-xvf archive" or
+// "--no-color", into the syntax of the flag package. Translation stops
+// at the "--" terminator, at the first positional argument unless
+// interspersed is set, or at a positional argument naming a subcommand
+// according to isCommand, whose flags are its own.
+func gnuArgs(fs *flag.FlagSet, args []string, interspersed bool, isCommand func(string) bool) ([]string, error) {
+	var (
+		res        []string
+		positional bool
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || (positional && !interspersed) {
+			return append(res, args[i:]...), nil
+		}
+		if !isFlag(arg) {
+			if !positional && isCommand != nil && isCommand(arg) {
+				return append(res, args[i:]...), nil
+			}
+			positional = true
+			res = append(res, arg)
+			continue
+		}
+
+		translated, needsValue, err := gnuFlag(fs, arg)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, translated...)
+		if needsValue && i+1 < len(args) {
+			i++
+			res = append(res, args[i])
+		}
+	}
+	return res, nil
+}
+
+// gnuFlag translates a single GNU style flag argument, reporting whether
+// the last flag expects its value in the next argument.
+func gnuFlag(fs *flag.FlagSet, arg string) (res []string, needsValue bool, err error) {
+	long := strings.HasPrefix(arg, "--")
+	name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+
+	if f := fs.Lookup(name); f != nil || hasValue || (!long && len(name) == 1) {
+		return []string{arg}, f != nil && !hasValue && !isBoolFlag(f), nil
+	}
+
+	if long {
+		if neg := strings.TrimPrefix(name, "no-"); neg != name {
+			if f := fs.Lookup(neg); f != nil && isBoolFlag(f) {
+				return []string{"-" + neg + "=false"}, false, nil
+			}
+		}
+		return []string{arg}, false, nil // let the flag package report it
+	}
+
+	// A cluster of shorthands or one-letter boolean flags, as in "-vvv",
+	// the last of which may take a value.
+	if f := fs.Lookup(name[:1]); f == nil || !(isShorthand(f) || isBoolFlag(f)) {
+		return []string{arg}, false, nil
+	}
+	for i, c := range name {
+		f := fs.Lookup(string(c))
+		if f == nil {
+			return nil, false, fmt.Errorf("unknown shorthand flag %q in %s", c, arg)
+		}
+		if isBoolFlag(f) {
+			res = append(res, "-"+string(c))
+			continue
+		}
+		if value := name[i+len(string(c)):]; value != "" {
+			return append(res, "-"+string(c)+"="+value), false, nil
+		}
+		return append(res, "-"+string(c)), true, nil
+	}
+	return res, false, nil
+}
@@ -0,0 +1,149 @@
+package commander_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/flags/commander"
+	"github.com/lucasepe/toolbox/logutils"
+)
+
+type tarCmd struct {
+	extract, verbose, color bool
+	file                    string
+	args                    []string
+}
+
+func (c *tarCmd) Name() string     { return "tar" }
+func (c *tarCmd) Synopsis() string { return "Manipulate archives." }
+func (c *tarCmd) Usage() string    { return "tar [-xv] [-f archive] [files...]" }
+
+func (c *tarCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.extract, "extract", false, "extract files")
+	f.BoolVar(&c.verbose, "verbose", false, "be verbose")
+	f.BoolVar(&c.color, "color", true, "colorize output")
+	f.StringVar(&c.file, "file", "", "the `archive` file")
+	commander.Shorthand(f, "extract", 'x')
+	commander.Shorthand(f, "verbose", 'v')
+	commander.Shorthand(f, "file", 'f')
+}
+
+func (c *tarCmd) Execute(f *flag.FlagSet) error {
+	c.args = f.Args()
+	return nil
+}
+
+func TestGNUFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		extract bool
+		verbose bool
+		color   bool
+		file    string
+		rest    string
+	}{
+		{[]string{"tar", "-xvf", "a.tar", "b"}, true, true, true, "a.tar", "b"},
+		{[]string{"tar", "-xfa.tar"}, true, false, true, "a.tar", ""},
+		{[]string{"tar", "--verbose", "--file=a.tar", "--no-color"}, false, true, false, "a.tar", ""},
+		{[]string{"tar", "-v", "--", "-x"}, false, true, true, "", "-x"},
+		{[]string{"tar", "b", "-xv"}, false, false, true, "", "b -xv"},
+	}
+	for _, tt := range tests {
+		cmd := &tarCmd{}
+		cdr, out := newTestCommander(tt.args...)
+		cdr.GNUFlags = true
+		cdr.Register(cmd, "")
+
+		if err := cdr.Execute(); err != nil {
+			t.Fatalf("%v: %v\n%s", tt.args, err, out.String())
+		}
+		if cmd.extract != tt.extract || cmd.verbose != tt.verbose || cmd.color != tt.color || cmd.file != tt.file {
+			t.Errorf("%v: unexpected flags %+v", tt.args, cmd)
+		}
+		if got := strings.Join(cmd.args, " "); got != tt.rest {
+			t.Errorf("%v: want args %q, got %q", tt.args, tt.rest, got)
+		}
+	}
+}
+
+func TestGNUFlagsBind(t *testing.T) {
+	t.Setenv("X_TAR_VERBOSE", "false")
+
+	cmd := &tarCmd{}
+	cdr, out := newTestCommander("tar", "-v")
+	cdr.GNUFlags = true
+	cdr.EnvPrefix = "X"
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	if !cmd.verbose {
+		t.Fatal("want the shorthand to take precedence over the environment")
+	}
+}
+
+func TestGNUFlagsUnknownShorthand(t *testing.T) {
+	cdr, out := newTestCommander("tar", "-xzf", "a.tar")
+	cdr.GNUFlags = true
+	cdr.Register(&tarCmd{}, "")
+
+	err := cdr.Execute()
+	if commander.ExitCode(err) != commander.ExitUsageError {
+		t.Fatalf("want a usage error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `unknown shorthand flag 'z' in -xzf`) {
+		t.Fatalf("want the unknown shorthand reported, got:\n%s", out.String())
+	}
+}
+
+func TestPrintDefaultsShorthand(t *testing.T) {
+	var out bytes.Buffer
+	fs := flag.NewFlagSet("tar", flag.ContinueOnError)
+	fs.SetOutput(&out)
+	(&tarCmd{}).SetFlags(fs)
+
+	commander.PrintDefaults(fs, "Flags:\n")
+	for _, want := range []string{
+		"  -v, --verbose ",
+		"  -f, --file archive ",
+		"      --color ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "  -v ") {
+		t.Errorf("want shorthands listed with their flag, got:\n%s", out.String())
+	}
+}
+
+type logCmd struct {
+	testCmd
+	filter logutils.LevelFilter
+}
+
+func (c *logCmd) SetFlags(f *flag.FlagSet) {
+	c.filter = logutils.LevelFilter{
+		Levels:   []logutils.LogLevel{"DEBUG", "INFO", "WARN", "ERROR"},
+		MinLevel: "ERROR",
+	}
+	flags.LogFlags(f, &c.filter)
+}
+
+func TestGNUFlagsCount(t *testing.T) {
+	cmd := &logCmd{testCmd: testCmd{name: "log"}}
+	cdr, out := newTestCommander("log", "-vv", "-log-level", "warn", "-v")
+	cdr.GNUFlags = true
+	cdr.Register(cmd, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatalf("%v\n%s", err, out.String())
+	}
+	if cmd.filter.MinLevel != "DEBUG" {
+		t.Fatalf("want the level lowered from WARN by 3, got %s", cmd.filter.MinLevel)
+	}
+}
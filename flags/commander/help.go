@@ -0,0 +1,241 @@
+package commander
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/lucasepe/toolbox/text"
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// The default templates of the help output. Besides the functions
+// predefined by text/template, the templates can call:
+//
+//	heading s          s styled as a heading (see Commander.Color)
+//	wrap n s           s wrapped to the output width and indented by n spaces
+//	rows r             the []HelpRow r laid out in two columns
+//	join s sep         strings.Join
+//	explainGroup g     the output of ExplainGroup for the *CommandGroup g
+//
+// The explainGroup function is only available to UsageTemplate.
+const (
+	// DefaultUsageTemplate is executed with a UsageHelp.
+	DefaultUsageTemplate = `{{with .Banner}}{{wrap 0 .}}
+{{end}}{{heading "Usage:"}}
+   {{.Name}} <command>
+
+{{range .Groups}}{{explainGroup .}}{{end}}
+{{- if .ImportantFlags}}{{heading (printf "Top-level flags (use \"%s flags\" for a full list):" .Name)}}
+{{rows .ImportantFlags}}
+{{- else if .HasFlags}}Use "{{.Name}} flags" for a list of top-level flags
+{{end}}`
+
+	// DefaultGroupTemplate is executed with a GroupHelp.
+	DefaultGroupTemplate = `{{if .Name}}{{heading (printf "Commands for %s:" .Name)}}{{else}}{{heading "Commands:"}}{{end}}
+{{rows .Commands}}
+`
+
+	// DefaultCommandTemplate is executed with a CommandHelp.
+	DefaultCommandTemplate = `{{heading "Synopsis:"}}
+{{wrap 3 .Synopsis}}
+
+{{heading "Usage:"}}
+   {{.Usage}}
+
+{{with .Args}}{{heading "Arguments:"}}
+{{rows .}}
+{{end}}
+{{- with .Aliases}}{{heading "Aliases:"}}
+   {{join . ", "}}
+
+{{end}}
+{{- with .Deprecated}}{{heading "Deprecated:"}}
+{{wrap 3 .}}
+
+{{end}}
+{{- with .Warnings}}{{heading "Warnings:"}}
+{{range .}}{{wrap 3 .}}
+{{end}}
+{{end}}
+{{- with .Flags}}{{heading "Flags:"}}
+{{rows .}}{{end}}
+{{- with .Constraints}}
+{{heading "Constraints:"}}
+{{range .}}{{wrap 3 .}}
+{{end}}{{end}}
+{{- with .GlobalFlags}}
+{{heading "Global flags:"}}
+{{rows .}}{{end}}
+{{- with .Subcommands}}
+{{heading "Subcommands:"}}
+{{rows .}}
+Use "{{$.HelpCommand}} <command>" for more information about a subcommand.
+{{end}}`
+)
+
+// A HelpRow is a line of a two-column layout of the help output: a term,
+// such as a command or flag name, and its description.
+type HelpRow struct {
+	Term        string // the term, including its indentation
+	Description string
+}
+
+// A UsageHelp is the data of the UsageTemplate.
+type UsageHelp struct {
+	Name           string
+	Banner         string
+	Groups         []*CommandGroup // the command groups, including the plugins
+	HasFlags       bool            // whether there are top-level flags
+	ImportantFlags []HelpRow       // the important top-level flags
+}
+
+// A GroupHelp is the data of the GroupTemplate.
+type GroupHelp struct {
+	Name     string    // the group name, empty for the default group
+	Commands []HelpRow // the visible commands and their synopses
+}
+
+// A CommandHelp is the data of the CommandTemplate.
+type CommandHelp struct {
+	Name        string
+	Path        string // the full command path, such as "tool remote add"
+	Synopsis    string
+	Usage       string
+	Args        []HelpRow // the named positional arguments
+	Aliases     []string
+	Deprecated  string
+	Warnings    []string // the flags that cannot be bound, and why
+	Flags       []HelpRow
+	Constraints []string  // the flag constraints, except the required flags
+	GlobalFlags []HelpRow // the persistent top-level flags
+	Subcommands []HelpRow // the visible subcommands and their synopses
+	HelpCommand string    // the help command line for the subcommands
+}
+
+// defaultWidth is the width of the help output when it is not written
+// to a terminal.
+const defaultWidth = 80
+
+// A helpWriter is the buffer a help template is rendered into, carrying
+// the properties of the final output.
+type helpWriter struct {
+	bytes.Buffer
+	width int
+	tty   bool
+}
+
+// widthOf returns the width the help output written to w is wrapped to:
+// Width when set, or else the width of the terminal, $COLUMNS or 80.
+func (cdr *Commander) widthOf(w io.Writer) int {
+	if cdr.Width > 0 {
+		return cdr.Width
+	}
+	return termWidth(w)
+}
+
+func termWidth(w io.Writer) int {
+	if hw, ok := w.(*helpWriter); ok {
+		return hw.width
+	}
+	if f, ok := w.(*os.File); ok {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+			return width
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return defaultWidth
+}
+
+// isTerminal reports whether w writes to a terminal.
+func isTerminal(w io.Writer) bool {
+	switch w := w.(type) {
+	case *helpWriter:
+		return w.tty
+	case *os.File:
+		return term.IsTerminal(int(w.Fd()))
+	}
+	return false
+}
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+
+// render executes the help template tmpl with data, writing the result
+// to w. ANSI escape sequences are stripped unless w is a terminal. An
+// invalid template is reported in the output rather than panicking.
+func (cdr *Commander) render(w io.Writer, tmpl string, data interface{}, funcs template.FuncMap) {
+	hw := &helpWriter{width: cdr.widthOf(w), tty: isTerminal(w)}
+	t := template.New("help").Funcs(template.FuncMap{
+		"heading": func(s string) string {
+			if cdr.Color {
+				return "\x1b[1m" + s + "\x1b[0m"
+			}
+			return s
+		},
+		"wrap": func(indent int, s string) string { return wrapIndent(s, indent, hw.width) },
+		"rows": func(rows []HelpRow) string { return formatRows(rows, hw.width) },
+		"join": strings.Join,
+	}).Funcs(funcs)
+	if _, err := t.Parse(tmpl); err != nil {
+		fmt.Fprintf(hw, "help template: %v\n", err)
+	} else if err := t.Execute(hw, data); err != nil {
+		fmt.Fprintf(hw, "\nhelp template: %v\n", err)
+	}
+
+	out := hw.String()
+	if !hw.tty {
+		out = ansiEscape.ReplaceAllString(out, "")
+	}
+	io.WriteString(w, out)
+}
+
+// minWrapWidth is the narrowest width text is wrapped to.
+const minWrapWidth = 20
+
+// wrapIndent wraps s to width and indents each line by indent spaces.
+func wrapIndent(s string, indent, width int) string {
+	lim := width - indent
+	if lim < minWrapWidth {
+		lim = minWrapWidth
+	}
+	return text.Indent(text.WrapString(s, uint(lim)), strings.Repeat(" ", indent))
+}
+
+// formatRows lays out rows in two columns, aligning the descriptions
+// three spaces after the longest term and wrapping them to width with a
+// hanging indentation. When the terms take more than half of the width,
+// each description is written below its term instead.
+func formatRows(rows []HelpRow, width int) string {
+	col := 0
+	for _, r := range rows {
+		if n := runewidth.StringWidth(r.Term); n > col {
+			col = n
+		}
+	}
+	col += 3
+
+	var sb strings.Builder
+	for _, r := range rows {
+		switch {
+		case r.Description == "":
+			sb.WriteString(r.Term + "\n")
+		case col > width/2:
+			indent := len(r.Term) - len(strings.TrimLeft(r.Term, " ")) + 4
+			sb.WriteString(r.Term + "\n")
+			sb.WriteString(wrapIndent(r.Description, indent, width) + "\n")
+		default:
+			pad := strings.Repeat(" ", col-runewidth.StringWidth(r.Term))
+			desc := wrapIndent(r.Description, col, width)
+			sb.WriteString(r.Term + pad + desc[col:] + "\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package commander_test
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+)
+
+type longCmd struct {
+	testCmd
+}
+
+func (c *longCmd) Synopsis() string {
+	return "Print the arguments given on the command line, after expanding the environment variables they reference."
+}
+
+func TestHelpWrap(t *testing.T) {
+	cdr, out := newTestCommander("help")
+	cdr.Width = 40
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&longCmd{testCmd{name: "echo"}}, "")
+
+	cdr.Execute()
+	for _, line := range strings.Split(out.String(), "\n") {
+		if len(line) > 40 {
+			t.Errorf("want lines wrapped to 40 columns, got %q", line)
+		}
+	}
+	if !strings.Contains(out.String(), "   echo   Print the arguments given on\n          the command line,") {
+		t.Fatalf("want a hanging indentation, got:\n%s", out.String())
+	}
+}
+
+func TestHelpTemplate(t *testing.T) {
+	cdr, out := newTestCommander("help", "print")
+	cdr.CommandTemplate = `{{heading .Path}}: {{.Synopsis}}{{range .Flags}} [{{.Term}}]{{end}}`
+	cdr.Color = true
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&testCmd{name: "print"}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	// The output is not a terminal, so the heading style is stripped.
+	if got, want := out.String(), "tool print: The print command. [  -verbose]"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+type modeCmd struct {
+	testCmd
+}
+
+func (c *modeCmd) SetFlags(f *flag.FlagSet) {
+	mode := flags.NewEnumOf(map[string]int{"fast": 1, "exact": 2}).
+		Describe("fast", "skip the checks", "f").
+		Describe("exact", "check everything")
+	f.Var(mode, "mode", "`mode` the mode to run in")
+}
+
+func TestHelpChoices(t *testing.T) {
+	cdr, out := newTestCommander("help", "run")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&modeCmd{testCmd{name: "run"}}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	want := `  -mode mode   the mode to run in
+                 exact  check everything
+                 fast   skip the checks (f)
+`
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("want the choices described, got:\n%s", out.String())
+	}
+}
+
+func TestHelpTemplateError(t *testing.T) {
+	cdr, out := newTestCommander("help", "print")
+	cdr.CommandTemplate = `{{.Synopsis`
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&testCmd{name: "print"}, "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out.String(), "help template: ") {
+		t.Fatalf("want the parse error reported, got %q", out.String())
+	}
+}
@@ -0,0 +1,112 @@
+package commander
+
+import (
+	"context"
+	"flag"
+)
+
+// An Invocation describes a resolved command about to be executed.
+type Invocation struct {
+	Command  Command       // the resolved command
+	Path     []string      // the command names, such as ["remote", "add"]
+	Group    string        // the group of the top-level command
+	Flags    *flag.FlagSet // the parsed flags of the command
+	TopFlags *flag.FlagSet // the top-level flags
+	Args     []string      // the arguments left after the flags
+}
+
+// A Handler executes an invocation.
+type Handler func(ctx context.Context, inv *Invocation) error
+
+// A Middleware wraps the execution of commands. It can act before and
+// after calling next, inspect or replace the returned error, or not call
+// next at all.
+type Middleware func(next Handler) Handler
+
+// A MiddlewareCommand is a Command with its own middleware, applied
+// after the global and group middleware.
+type MiddlewareCommand interface {
+	Command
+
+	// Middleware returns the middleware wrapping the command.
+	Middleware() []Middleware
+}
+
+// Use adds middleware applied to every command. Middleware run in the
+// order they are added: global middleware first, then group middleware
+// (see UseGroup), then the middleware of the command itself.
+func (cdr *Commander) Use(mw ...Middleware) {
+	cdr.middleware = append(cdr.middleware, mw...)
+}
+
+// UseGroup adds middleware applied to the commands of the named group,
+// including their subcommands.
+func (cdr *Commander) UseGroup(group string, mw ...Middleware) {
+	if cdr.groupMiddleware == nil {
+		cdr.groupMiddleware = make(map[string][]Middleware)
+	}
+	cdr.groupMiddleware[group] = append(cdr.groupMiddleware[group], mw...)
+}
+
+// Before returns a Middleware calling fn before the command. When fn
+// returns an error the command is not executed.
+func Before(fn func(ctx context.Context, inv *Invocation) error) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			if err := fn(ctx, inv); err != nil {
+				return err
+			}
+			return next(ctx, inv)
+		}
+	}
+}
+
+// After returns a Middleware calling fn after the command, with the
+// error it returned. The result of fn replaces that error.
+func After(fn func(ctx context.Context, inv *Invocation, err error) error) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, inv *Invocation) error {
+			return fn(ctx, inv, next(ctx, inv))
+		}
+	}
+}
+
+// invoke executes inv through the applicable middleware chain.
+func (cdr *Commander) invoke(ctx context.Context, inv *Invocation) error {
+	var chain []Middleware
+	chain = append(chain, cdr.middleware...)
+	chain = append(chain, cdr.groupMiddleware[inv.Group]...)
+	if mc, ok := inv.Command.(MiddlewareCommand); ok {
+		chain = append(chain, mc.Middleware()...)
+	}
+
+	h := Handler(execute)
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	return h(ctx, inv)
+}
+
+// execute is the innermost Handler, executing the command itself.
+func execute(ctx context.Context, inv *Invocation) error {
+	switch cmd := inv.Command.(type) {
+	case *plugin:
+		return cmd.run(inv.Args)
+	case ContextCommand:
+		return cmd.Run(ctx, inv.Flags)
+	}
+	return inv.Command.Execute(inv.Flags)
+}
+
+// groupOf returns the name of the group of the named top-level command.
+func (cdr *Commander) groupOf(name string) string {
+	for _, group := range cdr.commands {
+		if findCommand(group.commands, name) != nil {
+			return group.name
+		}
+	}
+	if findCommand(cdr.pluginCommands(), name) != nil {
+		return PluginGroup
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package commander_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type wrappedCmd struct {
+	testCmd
+	mw []commander.Middleware
+}
+
+func (c *wrappedCmd) Middleware() []commander.Middleware { return c.mw }
+
+func trace(calls *[]string, name string) commander.Middleware {
+	return func(next commander.Handler) commander.Handler {
+		return func(ctx context.Context, inv *commander.Invocation) error {
+			*calls = append(*calls, name+">"+strings.Join(inv.Path, " "))
+			err := next(ctx, inv)
+			*calls = append(*calls, name+"<")
+			return err
+		}
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	add := &wrappedCmd{testCmd: testCmd{name: "add"}, mw: []commander.Middleware{trace(&calls, "cmd")}}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add}}}
+
+	cdr, _ := newTestCommander("remote", "add", "x")
+	cdr.Register(remote, "remotes")
+	cdr.Use(trace(&calls, "global1"), trace(&calls, "global2"))
+	cdr.UseGroup("remotes", trace(&calls, "group"))
+	cdr.UseGroup("other", trace(&calls, "other"))
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"global1>remote add", "global2>remote add", "group>remote add", "cmd>remote add",
+		"cmd<", "group<", "global2<", "global1<",
+	}
+	if !reflect.DeepEqual(calls, want) {
+		t.Fatalf("want %q, got %q", want, calls)
+	}
+	if !add.ran {
+		t.Fatal("want command executed")
+	}
+}
+
+func TestBeforeAfter(t *testing.T) {
+	boom := errors.New("boom")
+	cmd := &failCmd{testCmd: testCmd{name: "run"}, err: boom}
+
+	var seen error
+	var top *flag.FlagSet
+	cdr, _ := newTestCommander("run", "-verbose")
+	cdr.Register(cmd, "")
+	cdr.Use(commander.After(func(ctx context.Context, inv *commander.Invocation, err error) error {
+		seen, top = err, inv.TopFlags
+		if inv.Flags.Lookup("verbose").Value.String() != "true" {
+			t.Error("want parsed command flags")
+		}
+		return nil
+	}))
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatalf("want error replaced by middleware, got %v", err)
+	}
+	if seen != boom || top == nil || top.Name() != "tool" {
+		t.Fatalf("want command error and top-level flags, got %v %v", seen, top)
+	}
+
+	denied := errors.New("denied")
+	cmd = &failCmd{testCmd: testCmd{name: "run"}}
+	cdr, _ = newTestCommander("run")
+	cdr.Register(cmd, "")
+	cdr.Use(commander.Before(func(ctx context.Context, inv *commander.Invocation) error {
+		return denied
+	}))
+
+	if err := cdr.Execute(); err != denied {
+		t.Fatalf("want %v, got %v", denied, err)
+	}
+}
@@ -0,0 +1,98 @@
+package commander
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+// PersistentFlag marks top-level flags as persistent, which means they
+// are also accepted after the command name, at any level of the command
+// tree, as in "tool remote add -verbose origin". A flag of a command with
+// the same name takes precedence over a persistent flag within that
+// command.
+func (cdr *Commander) PersistentFlag(names ...string) {
+	for _, name := range names {
+		if cdr.topFlags.Lookup(name) == nil {
+			panic(fmt.Sprintf("Persistent flag (%s) is not defined", name))
+		}
+		cdr.persistent = append(cdr.persistent, name)
+	}
+}
+
+// persistentFlags returns the top-level flags accepted by every command
+// in lexicographical order: the ones marked by PersistentFlag, or all of
+// them when Interspersed is set.
+func (cdr *Commander) persistentFlags() []*flag.Flag {
+	if cdr.topFlags == nil {
+		return nil
+	}
+
+	var res []*flag.Flag
+	if cdr.Interspersed {
+		cdr.topFlags.VisitAll(func(f *flag.Flag) { res = append(res, f) })
+		return res
+	}
+
+	sort.Strings(cdr.persistent)
+	for _, name := range cdr.persistent {
+		res = append(res, cdr.topFlags.Lookup(name))
+	}
+	return res
+}
+
+// inheritFlags defines in fs the persistent top-level flags not defined
+// by the command, sharing their values, and returns their names.
+func (cdr *Commander) inheritFlags(fs *flag.FlagSet) map[string]bool {
+	inherited := make(map[string]bool)
+	for _, f := range cdr.persistentFlags() {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+			inherited[f.Name] = true
+		}
+	}
+	return inherited
+}
+
+// parseArgs parses args with fs, translated first from GNU style when
+// GNUFlags is set. When Interspersed is set, flags may
+// follow positional arguments, up to a "--" terminator, unless the first
+// positional argument names a subcommand according to isCommand: the
+// remaining arguments are then left to the subcommand.
+func (cdr *Commander) parseArgs(fs *flag.FlagSet, args []string, isCommand func(string) bool) error {
+	if cdr.GNUFlags {
+		var err error
+		if args, err = gnuArgs(fs, args, cdr.Interspersed, isCommand); err != nil {
+			fmt.Fprintln(fs.Output(), err)
+			fs.Usage()
+			return err
+		}
+	}
+	if !cdr.Interspersed {
+		return fs.Parse(args)
+	}
+
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		rest := fs.Args()
+		if n := len(args) - len(rest); n > 0 && args[n-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		if len(positional) == 0 && isCommand != nil && isCommand(rest[0]) {
+			positional = rest
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	// Leave the positional arguments in fs.Args.
+	return fs.Parse(append([]string{"--"}, positional...))
+}
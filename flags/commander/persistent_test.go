@@ -0,0 +1,106 @@
+package commander_test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+func newFlagCommander(t *testing.T) (*commander.Commander, *flag.FlagSet, *bool, *bytes.Buffer) {
+	t.Helper()
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	debug := fs.Bool("debug", false, "enable debugging")
+
+	var out bytes.Buffer
+	cdr := commander.New(fs, "tool")
+	cdr.Output = &out
+	cdr.Error = &out
+	return cdr, fs, debug, &out
+}
+
+func TestPersistentFlag(t *testing.T) {
+	cdr, fs, debug, _ := newFlagCommander(t)
+	cdr.PersistentFlag("debug")
+
+	add := &testCmd{name: "add"}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add}}}
+	cdr.Register(remote, "")
+
+	fs.Parse([]string{"remote", "add", "-debug", "origin"})
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !*debug {
+		t.Fatal("want the persistent flag set from the nested command")
+	}
+	if !add.ran || strings.Join(add.args, " ") != "origin" {
+		t.Fatalf("want add run with args [origin], got %v", add.args)
+	}
+}
+
+func TestPersistentFlagShadowed(t *testing.T) {
+	cdr, fs, _, _ := newFlagCommander(t)
+	verbose := fs.Bool("verbose", false, "be verbose")
+	cdr.PersistentFlag("verbose")
+
+	cmd := &testCmd{name: "print"}
+	cdr.Register(cmd, "")
+
+	fs.Parse([]string{"print", "-verbose"})
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if *verbose || !cmd.verbose {
+		t.Fatal("want the command flag to take precedence over the persistent flag")
+	}
+}
+
+func TestInterspersed(t *testing.T) {
+	cdr, fs, debug, _ := newFlagCommander(t)
+	cdr.Interspersed = true
+
+	add := &testCmd{name: "add"}
+	remote := &parentCmd{testCmd{name: "remote", children: []commander.Command{add}}}
+	cdr.Register(remote, "")
+
+	fs.Parse([]string{"remote", "add", "origin", "-verbose", "url", "-debug", "--", "-x"})
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !add.verbose || remote.verbose || !*debug {
+		t.Fatal("want the flags after positional args parsed by the leaf command")
+	}
+	if got := strings.Join(add.args, " "); got != "origin url -x" {
+		t.Fatalf("want args [origin url -x], got %v", add.args)
+	}
+}
+
+func TestInterspersedUnknownFlag(t *testing.T) {
+	cdr, fs, _, _ := newFlagCommander(t)
+	cdr.Interspersed = true
+	cdr.Register(&testCmd{name: "print"}, "")
+
+	fs.Parse([]string{"print", "text", "-verbos"})
+	err := cdr.Execute()
+	if commander.ExitCode(err) != commander.ExitUsageError {
+		t.Fatalf("want a usage error, got %v", err)
+	}
+}
+
+func TestHelpGlobalFlags(t *testing.T) {
+	cdr, fs, _, out := newFlagCommander(t)
+	cdr.PersistentFlag("debug")
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(&testCmd{name: "print"}, "")
+
+	fs.Parse([]string{"help", "print"})
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Global flags:\n  -debug") {
+		t.Fatalf("want the persistent flags in the help, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,154 @@
+package commander
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/lucasepe/toolbox/xdg"
+)
+
+// PluginGroup is the name of the command group listing the plugins.
+const PluginGroup = "Plugins"
+
+// PluginDir returns the directory searched for plugins of the named
+// program before $PATH: "<name>/plugins" under xdg.DataDir().
+func PluginDir(name string) string {
+	return filepath.Join(xdg.DataDir(), name, "plugins")
+}
+
+// A plugin is a Command running an external executable named
+// "<tool>-<command>".
+type plugin struct {
+	cdr  *Commander
+	name string
+	path string
+}
+
+func (p *plugin) Name() string { return p.name }
+func (p *plugin) Synopsis() string {
+	return fmt.Sprintf("Run the %s plugin.", filepath.Base(p.path))
+}
+func (p *plugin) SetFlags(*flag.FlagSet) {}
+func (p *plugin) Usage() string {
+	return fmt.Sprintf(`%s [<args>...]
+
+Runs the external plugin %s, passing all the arguments through.
+`, p.name, p.path)
+}
+func (p *plugin) Execute(f *flag.FlagSet) error {
+	return p.run(f.Args())
+}
+
+// run executes the plugin with args, connecting it to the input and
+// output of the commander. When the plugin exits with a non-zero status,
+// the returned error is an ExitCoder carrying that status, or 128 plus
+// the signal number when the plugin is killed by a signal, as shells do.
+func (p *plugin) run(args []string) error {
+	cmd := exec.Command(p.path, args...)
+	cmd.Stdin = p.cdr.Input
+	cmd.Stdout = p.cdr.Output
+	cmd.Stderr = p.cdr.Error
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == -1 {
+			err = &signaledError{exitErr}
+		}
+		return fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+	return nil
+}
+
+// A signaledError is the error of a plugin killed by a signal.
+type signaledError struct {
+	*exec.ExitError
+}
+
+// ExitCode returns 128 plus the signal number, or ExitInterrupted when
+// the signal is unknown.
+func (e *signaledError) ExitCode() int {
+	if ws, ok := e.Sys().(interface{ Signal() syscall.Signal }); ok && ws.Signal() > 0 {
+		return 128 + int(ws.Signal())
+	}
+	return ExitInterrupted
+}
+
+// Unwrap returns the underlying *exec.ExitError.
+func (e *signaledError) Unwrap() error { return e.ExitError }
+
+// pluginCommands returns the plugins found in PluginDir and in the
+// directories of $PATH, sorted by name, when Plugins is enabled. The
+// first executable found for a name wins; registered commands always
+// take precedence over plugins.
+func (cdr *Commander) pluginCommands() []Command {
+	if !cdr.Plugins {
+		return nil
+	}
+	if cdr.plugins != nil {
+		return cdr.plugins
+	}
+
+	path, _ := cdr.LookupEnv("PATH")
+	dirs := append([]string{PluginDir(cdr.name)}, filepath.SplitList(path)...)
+	prefix := cdr.name + "-"
+	seen := make(map[string]bool)
+	plugins := []Command{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name(), prefix)
+			if !ok || seen[name] || cdr.lookupRegistered(name) != nil {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, &plugin{cdr: cdr, name: name, path: path})
+		}
+	}
+
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name() < plugins[j].Name()
+	})
+	cdr.plugins = plugins
+	return plugins
+}
+
+// pluginName returns the command name of the executable file, if it is
+// named after prefix.
+func pluginName(file, prefix string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(file))
+		if ext != ".exe" && ext != ".bat" && ext != ".cmd" {
+			return "", false
+		}
+		file = file[:len(file)-len(ext)]
+	}
+	if !strings.HasPrefix(file, prefix) || len(file) == len(prefix) {
+		return "", false
+	}
+	return file[len(prefix):], true
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	if err != nil || fi.IsDir() {
+		return false
+	}
+	return runtime.GOOS == "windows" || fi.Mode()&0o111 != 0
+}
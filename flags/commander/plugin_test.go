@@ -0,0 +1,62 @@
+package commander_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts need a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	writePlugin(t, dir, "tool-hello", `echo "hello $@"`)
+	writePlugin(t, dir, "tool-fail", `exit 3`)
+	writePlugin(t, dir, "tool-killed", `kill -TERM $$`)
+	writePlugin(t, dir, "tool-run", `echo shadowed`)
+	t.Setenv("PATH", dir)
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	tests := []struct {
+		args []string
+		want string
+		code int
+	}{
+		{[]string{"hello", "-x", "world"}, "hello -x world", commander.ExitSuccess},
+		{[]string{"fail"}, "", 3},
+		{[]string{"killed"}, "", 128 + 15},
+		{[]string{"run"}, "", commander.ExitSuccess},
+		{[]string{"help"}, "Commands for Plugins:", commander.ExitSuccess},
+	}
+	for _, tt := range tests {
+		cmd := &testCmd{name: "run"}
+		cdr, out := newTestCommander(tt.args...)
+		cdr.Plugins = true
+		cdr.Register(cdr.HelpCommand(), "")
+		cdr.Register(cmd, "")
+
+		if code := commander.ExitCode(cdr.Execute()); code != tt.code {
+			t.Errorf("%q: want exit code %d, got %d", tt.args, tt.code, code)
+		}
+		if !strings.Contains(out.String(), tt.want) {
+			t.Errorf("%q: want %q in output, got:\n%s", tt.args, tt.want, out.String())
+		}
+		if strings.Contains(out.String(), "shadowed") {
+			t.Errorf("%q: want registered commands to shadow plugins", tt.args)
+		}
+	}
+}
@@ -3,28 +3,105 @@ package commander
 import (
 	"flag"
 	"fmt"
+	"io"
 	"strings"
-	"text/tabwriter"
+
+	"github.com/lucasepe/toolbox/flags"
 )
 
 // PrintDefaults prints, to standard error unless configured otherwise, the
 // default values of all defined command-line flags in the set. See the
 // documentation for the global function PrintDefaults for more information.
 func PrintDefaults(fs *flag.FlagSet, hdr string) {
+	printDefaults(fs, hdr, nil)
+}
+
+// PrintDefaults is like the package-level PrintDefaults, also showing
+// how the flags are bound to the environment and the config file: the
+// environment variable name of each flag and, when bound, its effective
+// value and source. The path holds the names of the commands owning fs,
+// and is empty for the top-level flags.
+func (cdr *Commander) PrintDefaults(fs *flag.FlagSet, hdr string, path ...string) {
+	printDefaults(fs, hdr, cdr.annotator(path, nil))
+}
+
+// printDefaults is like PrintDefaults, appending the non-empty result
+// of annotate, when not nil, to the usage of each flag.
+func printDefaults(fs *flag.FlagSet, hdr string, annotate func(*flag.Flag) string) {
 	if countFlags(fs) > 0 {
 		fmt.Fprint(fs.Output(), hdr)
+		io.WriteString(fs.Output(), formatRows(flagRows(fs.VisitAll, annotate), termWidth(fs.Output())))
+	}
+}
+
+// flagRows returns the help rows of the visited flags, omitting the
+// shorthands, which are shown along with their flag.
+func flagRows(visit func(func(*flag.Flag)), annotate func(*flag.Flag) string) []HelpRow {
+	var rows []HelpRow
+	shorts := shorthands(visit)
+	visit(func(f *flag.Flag) {
+		if !isShorthand(f) {
+			rows = append(rows, flagRow(f, shorts, annotate))
+		}
+	})
+	return rows
+}
+
+// flagRow returns the help row of the flag f: its name and type, and its
+// usage followed by the non-empty result of annotate, when not nil.
+func flagRow(f *flag.Flag, shorts map[string]string, annotate func(*flag.Flag) string) HelpRow {
+	label := flagLabel(f, shorts)
+	if len(shorts) > 0 && strings.HasPrefix(label, "--") {
+		label = "    " + label // align with "-v, --verbose"
+	}
+	typ, desc := unquoteUsage(f)
+	if annotate != nil {
+		if note := annotate(f); note != "" {
+			desc += " " + note
+		}
+	}
+	return HelpRow{
+		Term:        strings.TrimRight("  "+label+" "+typ, " "),
+		Description: strings.TrimSpace(desc) + describeChoices(f.Value),
+	}
+}
+
+// describeChoices lists the choices of a flags.Chooser value on separate
+// lines, when at least one of them has a description.
+func describeChoices(v flag.Value) string {
+	c, ok := v.(flags.Chooser)
+	if !ok {
+		return ""
+	}
+	choices := c.ValidChoices()
+	width, described := 0, false
+	for _, info := range choices {
+		if len(info.Name) > width {
+			width = len(info.Name)
+		}
+		described = described || info.Description != ""
+	}
+	if !described {
+		return ""
+	}
 
-		tw := tabwriter.NewWriter(fs.Output(), 0, 3, 3, ' ', 0)
-		fs.VisitAll(func(f *flag.Flag) {
-			typ, desc := unquoteUsage(f)
-			fmt.Fprintf(tw, "  -%s %s\t%s\n", f.Name, typ, desc)
-		})
-		tw.Flush()
+	var sb strings.Builder
+	for _, info := range choices {
+		line := info.Name + strings.Repeat(" ", width-len(info.Name)+2) + info.Description
+		if len(info.Aliases) > 0 {
+			line += " (" + strings.Join(info.Aliases, ", ") + ")"
+		}
+		sb.WriteString("\n  " + strings.TrimSpace(line))
 	}
+	return sb.String()
 }
 
 func countFlags(fs *flag.FlagSet) (n int) {
-	fs.VisitAll(func(*flag.Flag) { n++ })
+	fs.VisitAll(func(f *flag.Flag) {
+		if !isShorthand(f) {
+			n++
+		}
+	})
 	return n
 }
 
@@ -33,9 +110,9 @@ func countFlags(fs *flag.FlagSet) (n int) {
 // Given "a `name` to show" it returns ("name", "a name to show").
 // If there are no back quotes, the name is an educated guess of the
 // type of the flag's value, or the empty string if the flag is boolean.
-func unquoteUsage(flag *flag.Flag) (name string, usage string) {
+func unquoteUsage(f *flag.Flag) (name string, usage string) {
 	// Look for a back-quoted name, but avoid the strings package.
-	usage = flag.Usage
+	usage = f.Usage
 	for i := 0; i < len(usage); i++ {
 		if usage[i] == '`' {
 			for j := i + 1; j < len(usage); j++ {
@@ -49,5 +126,21 @@ func unquoteUsage(flag *flag.Flag) (name string, usage string) {
 		}
 	}
 
+	name, _ = flag.UnquoteUsage(f)
 	return
 }
+
+// withDefaults returns an annotator noting the default value of a flag,
+// followed by the result of annotate when not nil.
+func withDefaults(annotate func(*flag.Flag) string) func(*flag.Flag) string {
+	return func(f *flag.Flag) string {
+		var note string
+		if def := defaultOf(f); def != "" {
+			note = fmt.Sprintf("(default %s)", def)
+		}
+		if annotate != nil {
+			note = strings.TrimSpace(note + " " + annotate(f))
+		}
+		return note
+	}
+}
@@ -0,0 +1,245 @@
+package commander
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Shell runs an interactive session: it reads command lines from Input,
+// splits them into arguments with shell-like quoting and dispatches them
+// to the registered commands, until "exit" or "quit" is entered or the
+// input ends. When Input is a terminal, the session supports line
+// editing, history and tab completion of commands and flags, and
+// Ctrl-C or Ctrl-D at the prompt ends it. Errors of a command are
+// printed to Error and do not end the session.
+//
+// Each line is executed like ExecuteContext executes the command line,
+// so that an interrupt cancels the running ContextCommand rather than
+// the session. Other commands keep the default handling of signals.
+func (cdr *Commander) Shell() error {
+	if err := cdr.bind(cdr.topFlags, nil, nil); err != nil {
+		fmt.Fprintln(cdr.Error, err)
+		return err
+	}
+
+	cdr.inShell = true
+	defer func() { cdr.inShell = false }()
+
+	if f, ok := cdr.Input.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return cdr.interactiveShell(f)
+	}
+
+	sc := bufio.NewScanner(cdr.Input)
+	for sc.Scan() {
+		if cdr.shellLine(sc.Text()) {
+			return nil
+		}
+	}
+	return sc.Err()
+}
+
+// interactiveShell runs the session on the terminal f.
+func (cdr *Commander) interactiveShell(f *os.File) error {
+	fd := int(f.Fd())
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{f, cdr.Output}, cdr.prompt())
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return cdr.completeLine(t, line, pos)
+	}
+
+	for {
+		if width, height, err := term.GetSize(fd); err == nil && width > 0 {
+			t.SetSize(width, height)
+		}
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		line, err := t.ReadLine()
+		term.Restore(fd, state)
+
+		if err == io.EOF {
+			fmt.Fprintln(cdr.Output)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if cdr.shellLine(line) {
+			return nil
+		}
+	}
+}
+
+// shellLine executes a command line of the session, reporting whether
+// the session should end.
+func (cdr *Commander) shellLine(line string) bool {
+	args, err := splitArgs(line)
+	if err != nil {
+		fmt.Fprintln(cdr.Error, err)
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+
+	switch args[0] {
+	case "exit", "quit":
+		return true
+	}
+
+	cdr.config = nil // reload the config file
+	err = cdr.dispatch(context.WithValue(context.Background(), signalsKey{}, true), args)
+	// Usage errors have already been explained.
+	if err != nil && !errors.Is(err, ErrUsage) && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintf(cdr.Error, "Error: %v\n", err)
+	}
+	return false
+}
+
+// prompt returns the prompt of the session.
+func (cdr *Commander) prompt() string {
+	if cdr.Prompt != "" {
+		return cdr.Prompt
+	}
+	return cdr.name + "> "
+}
+
+// completeLine completes the word before pos in line, listing the
+// candidates on w when there is more than one.
+func (cdr *Commander) completeLine(w io.Writer, line string, pos int) (string, int, bool) {
+	head, tail := line[:pos], line[pos:]
+	words, err := splitArgs(head)
+	if err != nil {
+		return "", 0, false
+	}
+	if head == "" || strings.HasSuffix(head, " ") {
+		words = append(words, "")
+	}
+
+	candidates := cdr.Complete(words)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	last := words[len(words)-1]
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(candidates) == 1 {
+		prefix += " "
+	} else if prefix == last {
+		fmt.Fprintln(w, strings.Join(candidates, "  "))
+	}
+	if !strings.HasSuffix(head, last) {
+		return "", 0, false
+	}
+
+	head = head[:len(head)-len(last)] + prefix
+	return head + tail, len(head), true
+}
+
+// splitArgs splits a command line into arguments, honouring single
+// quotes, double quotes and backslash escapes like a POSIX shell.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped, inArg = true, true
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("unterminated escape")
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
+
+// A shell is a Command implementing a "shell" command for a given
+// Commander.
+type shell Commander
+
+func (s *shell) Name() string { return "shell" }
+func (s *shell) Synopsis() string {
+	return "Start an interactive session."
+}
+func (s *shell) SetFlags(*flag.FlagSet) {}
+func (s *shell) Usage() string {
+	return `shell
+
+Starts an interactive session, reading commands from the terminal
+until "exit" or "quit" is entered.
+`
+}
+func (s *shell) Execute(f *flag.FlagSet) error {
+	if s.inShell {
+		fmt.Fprintln(s.Error, "Already in a shell session")
+		return nil
+	}
+	return (*Commander)(s).Shell()
+}
+
+// ShellCommand returns a Command which implements a "shell" subcommand,
+// starting an interactive session (see Shell).
+func (cdr *Commander) ShellCommand() Command {
+	return (*shell)(cdr)
+}
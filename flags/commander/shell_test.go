@@ -0,0 +1,113 @@
+package commander_test
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShell(t *testing.T) {
+	cmd := &testCmd{name: "print"}
+	cdr, out := newTestCommander()
+	cdr.Register(cdr.HelpCommand(), "")
+	cdr.Register(cmd, "")
+	cdr.Input = strings.NewReader(`
+help print
+stauts
+print -verbose 'hello world' "a \"quoted\" arg" back\ slash
+exit
+print never
+`)
+
+	if err := cdr.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"hello world", `a "quoted" arg`, "back slash"}
+	if !reflect.DeepEqual(cmd.args, want) || !cmd.verbose {
+		t.Fatalf("want args %q, got %q", want, cmd.args)
+	}
+	for _, want := range []string{`unknown command "stauts"`, "The print command."} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("want %q in:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestShellQuoteError(t *testing.T) {
+	cmd := &testCmd{name: "print"}
+	cdr, out := newTestCommander()
+	cdr.Register(cmd, "")
+	cdr.Input = strings.NewReader("print 'oops\nprint ok\n")
+
+	if err := cdr.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "unterminated ' quote") {
+		t.Errorf("want quote error, got:\n%s", out.String())
+	}
+	if !reflect.DeepEqual(cmd.args, []string{"ok"}) {
+		t.Errorf("want session to continue after an error, got %q", cmd.args)
+	}
+}
+
+func TestShellPlainSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("cannot send os.Interrupt on windows")
+	}
+
+	cmd := &signalCmd{testCmd: testCmd{name: "run"}, sigc: make(chan os.Signal, 1)}
+	signal.Notify(cmd.sigc, os.Interrupt)
+	defer signal.Stop(cmd.sigc)
+
+	cdr, out := newTestCommander()
+	cdr.Register(cmd, "")
+	cdr.Input = strings.NewReader("run\n")
+	if err := cdr.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "shutting down") {
+		t.Fatalf("want the signal left alone for plain commands, got:\n%s", out.String())
+	}
+}
+
+// readerFunc is an io.Reader calling a function.
+type readerFunc func([]byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) { return f(p) }
+
+func TestShellConfigReload(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.env")
+	if err := os.WriteFile(config, []byte("REMOTE_RETRIES=3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := &timeoutCmd{testCmd: testCmd{name: "remote"}}
+	cdr, _ := newTestCommander()
+	cdr.ConfigFile = config
+	cdr.Register(cmd, "")
+
+	// Edit the config file between the two lines.
+	edited := false
+	edit := readerFunc(func(p []byte) (int, error) {
+		if edited {
+			return 0, io.EOF
+		}
+		edited = true
+		if err := os.WriteFile(config, []byte("REMOTE_RETRIES=5\n"), 0o600); err != nil {
+			return 0, err
+		}
+		return copy(p, "remote\n"), nil
+	})
+	cdr.Input = io.MultiReader(strings.NewReader("remote\n"), edit)
+
+	if err := cdr.Shell(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.retries != 5 {
+		t.Fatalf("want the edited config file reloaded, got retries %d", cmd.retries)
+	}
+}
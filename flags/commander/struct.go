@@ -0,0 +1,423 @@
+package commander
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/text"
+)
+
+// A Runner is the options struct of a command built by FromStruct.
+type Runner interface {
+	// Run executes the command once the flags have been parsed into
+	// the struct fields.
+	Run(ctx context.Context, f *flag.FlagSet) error
+}
+
+// FromStruct returns a Command with the given name, synopsis and usage,
+// whose flags are the tagged fields of the struct pointed to by v and
+// whose execution calls v.Run. The supported tags are:
+//
+//	flag:"name"      the flag name ("-" skips the field)
+//	usage:"..."      the flag usage
+//	default:"..."    the default value, parsed like a command line value
+//	env:"NAME"       an environment variable overriding the default value
+//	                 when the flag is not given on the command line
+//	enum:"a,b,c"     the valid choices of a string or []string field
+//	prefix:"db-"     a prefix for the flag names of a nested struct
+//
+// Fields can be strings, bools, integers, floats, time.Durations,
+// slices of those (accumulated over repeated flags and split on
+// commas), or implement flag.Value, like the enum types of the flags
+// package. Struct fields without a flag tag, embedded or not, are
+// option groups whose own fields are flags.
+//
+// When v implements Aliaser, Hider, Deprecator, ArgsCommand,
+// ConstrainedCommand or MiddlewareCommand, so does the returned Command.
+//
+// The flags are defined anew each time the command runs, resetting the
+// tagged fields to their values when FromStruct was called, and then to
+// their default tags, so that no value given on a previous run, as in
+// a Shell session, is left over.
+func FromStruct(name, synopsis, usage string, v Runner) Command {
+	c := &structCmd{name: name, synopsis: synopsis, usage: usage, v: v}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.Elem().Kind() == reflect.Struct {
+		c.initial = reflect.New(rv.Elem().Type()).Elem()
+		c.initial.Set(rv.Elem())
+	}
+	return c
+}
+
+// A structCmd is a Command adapting a Runner.
+type structCmd struct {
+	name, synopsis, usage string
+	v                     Runner
+	initial               reflect.Value     // a copy of the options struct
+	env                   map[string]string // the env tags, by flag name
+}
+
+func (c *structCmd) Name() string     { return c.name }
+func (c *structCmd) Synopsis() string { return c.synopsis }
+func (c *structCmd) Usage() string    { return c.usage }
+
+func (c *structCmd) SetFlags(f *flag.FlagSet) {
+	rv := reflect.ValueOf(c.v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("commander: %s options must be a pointer to a struct, got %T", c.name, c.v))
+	}
+	c.env = make(map[string]string)
+	if err := structFlags(f, rv.Elem(), c.initial, "", c.env); err != nil {
+		panic(fmt.Sprintf("commander: %s options: %v", c.name, err))
+	}
+}
+
+func (c *structCmd) Execute(f *flag.FlagSet) error {
+	return c.v.Run(context.Background(), f)
+}
+
+func (c *structCmd) Run(ctx context.Context, f *flag.FlagSet) error {
+	return c.v.Run(ctx, f)
+}
+
+func (c *structCmd) flagEnv() map[string]string {
+	return c.env
+}
+
+func (c *structCmd) Aliases() []string {
+	if a, ok := c.v.(interface{ Aliases() []string }); ok {
+		return a.Aliases()
+	}
+	return nil
+}
+
+func (c *structCmd) Hidden() bool {
+	h, ok := c.v.(interface{ Hidden() bool })
+	return ok && h.Hidden()
+}
+
+func (c *structCmd) Deprecated() string {
+	if d, ok := c.v.(interface{ Deprecated() string }); ok {
+		return d.Deprecated()
+	}
+	return ""
+}
+
+func (c *structCmd) Args() ArgSpec {
+	if a, ok := c.v.(interface{ Args() ArgSpec }); ok {
+		return a.Args()
+	}
+	return ArgSpec{Max: -1}
+}
+
+func (c *structCmd) Constraints() []Constraint {
+	if cc, ok := c.v.(interface{ Constraints() []Constraint }); ok {
+		return cc.Constraints()
+	}
+	return nil
+}
+
+func (c *structCmd) Middleware() []Middleware {
+	if m, ok := c.v.(interface{ Middleware() []Middleware }); ok {
+		return m.Middleware()
+	}
+	return nil
+}
+
+var (
+	valueType    = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// structFlags defines a flag in f for each tagged field of the struct
+// sv, recursing into option groups, after resetting the field to its
+// value in the struct initial. The prefix is prepended to the flag
+// names, and the env tags are recorded in env.
+func structFlags(f *flag.FlagSet, sv, initial reflect.Value, prefix string, env map[string]string) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		field := st.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue // unexported
+		}
+		fv := sv.Field(i)
+		name, tagged := field.Tag.Lookup("flag")
+		if name == "-" {
+			continue
+		}
+
+		if !tagged {
+			if field.Type.Kind() == reflect.Struct && !reflect.PtrTo(field.Type).Implements(valueType) {
+				if err := structFlags(f, fv, initial.Field(i), prefix+field.Tag.Get("prefix"), env); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if name == "" {
+			name = text.ToKebab(field.Name)
+		}
+		fv.Set(initial.Field(i))
+		if err := structFlag(f, fv, field, prefix+name); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		if key := field.Tag.Get("env"); key != "" {
+			env[prefix+name] = key
+		}
+	}
+	return nil
+}
+
+// structFlag defines the flag name for the struct field fv.
+func structFlag(f *flag.FlagSet, fv reflect.Value, field reflect.StructField, name string) error {
+	usage := field.Tag.Get("usage")
+	def, hasDef := field.Tag.Lookup("default")
+	if key := field.Tag.Get("env"); key != "" {
+		usage = strings.TrimSpace(usage + " [$" + key + "]")
+	}
+
+	var value flag.Value
+	switch {
+	case fv.Addr().Type().Implements(valueType):
+		value = fv.Addr().Interface().(flag.Value)
+		if choices := field.Tag.Get("enum"); choices != "" {
+			setChoices(value, strings.Split(choices, ","))
+		}
+	case field.Tag.Get("enum") != "" && fv.Kind() == reflect.String:
+		e := &flags.Enum{Choices: strings.Split(field.Tag.Get("enum"), ",")}
+		value = &enumField{Enum: e, v: fv}
+	case field.Tag.Get("enum") != "" && fv.Type() == reflect.TypeOf([]string(nil)):
+		e := &flags.EnumsCSV{Choices: strings.Split(field.Tag.Get("enum"), ","), Accumulate: true}
+		value = &enumsField{EnumsCSV: e, v: fv}
+	case fv.Kind() == reflect.Slice:
+		if !isScalar(fv.Type().Elem()) {
+			return fmt.Errorf("unsupported type %s", fv.Type())
+		}
+		value = &sliceValue{v: fv}
+	case isScalar(fv.Type()):
+		if hasDef {
+			if err := parseScalar(fv, def); err != nil {
+				return fmt.Errorf("invalid default %q: %v", def, err)
+			}
+		}
+		if basicVar(f, fv, name, usage) {
+			return nil
+		}
+		value = &scalarValue{v: fv}
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+
+	if hasDef {
+		if err := value.Set(def); err != nil {
+			return fmt.Errorf("invalid default %q: %v", def, err)
+		}
+		if sv, ok := value.(*sliceValue); ok {
+			sv.set = false // values given on the command line replace the default
+		}
+		if ev, ok := value.(*enumsField); ok {
+			ev.set = false
+		}
+	}
+	f.Var(value, name, usage)
+	return nil
+}
+
+// basicVar defines the flag name with the flag.FlagSet method matching
+// the type of fv, if any, so that the help output shows the type name.
+func basicVar(f *flag.FlagSet, fv reflect.Value, name, usage string) bool {
+	switch p := fv.Addr().Interface().(type) {
+	case *string:
+		f.StringVar(p, name, *p, usage)
+	case *bool:
+		f.BoolVar(p, name, *p, usage)
+	case *int:
+		f.IntVar(p, name, *p, usage)
+	case *int64:
+		f.Int64Var(p, name, *p, usage)
+	case *uint:
+		f.UintVar(p, name, *p, usage)
+	case *uint64:
+		f.Uint64Var(p, name, *p, usage)
+	case *float64:
+		f.Float64Var(p, name, *p, usage)
+	case *time.Duration:
+		f.DurationVar(p, name, *p, usage)
+	default:
+		return false
+	}
+	return true
+}
+
+// setChoices sets the choices of the enum types of the flags package.
+func setChoices(v flag.Value, choices []string) {
+	switch fv := v.(type) {
+	case *flags.Enum:
+		fv.Choices = choices
+	case *flags.Enums:
+		fv.Choices = choices
+	case *flags.EnumsCSV:
+		fv.Choices = choices
+	case *flags.EnumSet:
+		fv.Choices = choices
+	case *flags.EnumSetCSV:
+		fv.Choices = choices
+	}
+}
+
+func isScalar(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// parseScalar parses s into the scalar value v.
+func parseScalar(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	}
+	return nil
+}
+
+// A scalarValue is a flag.Value setting a scalar struct field.
+type scalarValue struct {
+	v reflect.Value
+}
+
+func (sv *scalarValue) Set(s string) error { return parseScalar(sv.v, s) }
+
+func (sv *scalarValue) String() string {
+	if !sv.v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(sv.v.Interface())
+}
+
+func (sv *scalarValue) IsBoolFlag() bool {
+	return sv.v.IsValid() && sv.v.Kind() == reflect.Bool
+}
+
+// A sliceValue is a flag.Value appending to a slice struct field. Each
+// value is split on commas, and repeated flags accumulate.
+type sliceValue struct {
+	v   reflect.Value
+	set bool
+}
+
+func (sv *sliceValue) Set(s string) error {
+	if !sv.set {
+		sv.v.Set(reflect.MakeSlice(sv.v.Type(), 0, 0))
+		sv.set = true
+	}
+	for _, part := range strings.Split(s, ",") {
+		elem := reflect.New(sv.v.Type().Elem()).Elem()
+		if err := parseScalar(elem, strings.TrimSpace(part)); err != nil {
+			return err
+		}
+		sv.v.Set(reflect.Append(sv.v, elem))
+	}
+	return nil
+}
+
+func (sv *sliceValue) String() string {
+	if !sv.v.IsValid() {
+		return ""
+	}
+	parts := make([]string, sv.v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(sv.v.Index(i).Interface())
+	}
+	return strings.Join(parts, ",")
+}
+
+// An enumField is a flags.Enum storing its value in a string field.
+type enumField struct {
+	*flags.Enum
+	v reflect.Value
+}
+
+func (ef *enumField) Set(s string) error {
+	if err := ef.Enum.Set(s); err != nil {
+		return err
+	}
+	ef.v.SetString(ef.Value)
+	return nil
+}
+
+func (ef *enumField) String() string {
+	if ef.Enum == nil {
+		return ""
+	}
+	return ef.v.String()
+}
+
+// An enumsField is a flags.EnumsCSV storing its values in a []string
+// field.
+type enumsField struct {
+	*flags.EnumsCSV
+	v   reflect.Value
+	set bool
+}
+
+func (ef *enumsField) Set(s string) error {
+	if !ef.set {
+		ef.Values, ef.Texts = nil, nil
+		ef.set = true
+	}
+	if err := ef.EnumsCSV.Set(s); err != nil {
+		return err
+	}
+	ef.v.Set(reflect.ValueOf(append([]string(nil), ef.Values...)))
+	return nil
+}
+
+func (ef *enumsField) String() string {
+	if ef.EnumsCSV == nil {
+		return ""
+	}
+	return ef.EnumsCSV.String()
+}
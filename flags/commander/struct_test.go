@@ -0,0 +1,165 @@
+package commander_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+type dbOptions struct {
+	Host string `flag:"host" default:"localhost" usage:"database host"`
+	Port int    `flag:"port" default:"5432" usage:"database port"`
+}
+
+type serveOptions struct {
+	Addr    string        `flag:"addr" default:":8080" usage:"listen address"`
+	Timeout time.Duration `flag:"timeout" default:"5s" usage:"request timeout"`
+	Verbose bool          `flag:"verbose" usage:"be verbose"`
+	Format  string        `flag:"format" default:"json" enum:"json,yaml" usage:"output format"`
+	Tags    []string      `flag:"tag" usage:"tags to apply"`
+	Ports   []int         `flag:"expose" default:"80,443" usage:"exposed ports"`
+	Level   flags.Enum    `flag:"level" enum:"debug,info,warn"`
+	Token   string        `flag:"token" env:"SERVE_TEST_TOKEN"`
+	MaxConn int           `flag:""`
+	DB      dbOptions     `prefix:"db-"`
+	Ignored string        `flag:"-"`
+
+	ran  bool
+	args []string
+}
+
+func (o *serveOptions) Run(ctx context.Context, f *flag.FlagSet) error {
+	o.ran = true
+	o.args = f.Args()
+	return nil
+}
+
+func (o *serveOptions) Aliases() []string { return []string{"s"} }
+
+func TestFromStruct(t *testing.T) {
+	t.Setenv("SERVE_TEST_TOKEN", "secret")
+
+	opts := &serveOptions{}
+	cdr, _ := newTestCommander("s", "-verbose", "-timeout", "1m", "-format", "YAML",
+		"-tag", "a,b", "-tag", "c", "-expose", "8080", "-level", "info",
+		"-max-conn", "10", "-db-host", "db.local", "extra")
+	cdr.Register(commander.FromStruct("serve", "Start the server.", "serve [flags]", opts), "")
+
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !opts.ran || strings.Join(opts.args, " ") != "extra" {
+		t.Fatalf("want the command run with args [extra], got %v", opts.args)
+	}
+
+	want := serveOptions{
+		Addr:    ":8080",
+		Timeout: time.Minute,
+		Verbose: true,
+		Format:  "yaml",
+		Tags:    []string{"a", "b", "c"},
+		Ports:   []int{8080},
+		Token:   "secret",
+		MaxConn: 10,
+		DB:      dbOptions{Host: "db.local", Port: 5432},
+	}
+	got := *opts
+	got.Level, got.ran, got.args = flags.Enum{}, false, nil
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want options %+v, got %+v", want, got)
+	}
+	if opts.Level.Value != "info" {
+		t.Fatalf("want level info, got %q", opts.Level.Value)
+	}
+}
+
+func TestFromStructEnv(t *testing.T) {
+	t.Setenv("SERVE_TEST_TOKEN", "from-env")
+
+	opts := &serveOptions{}
+	cdr, _ := newTestCommander("serve", "-token", "from-flag")
+	cdr.Register(commander.FromStruct("serve", "Start the server.", "serve [flags]", opts), "")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Token != "from-flag" {
+		t.Fatalf("want the command line to take precedence, got %q", opts.Token)
+	}
+
+	type portOptions struct {
+		serveOptions
+		Port int `flag:"port" env:"SERVE_TEST_PORT"`
+	}
+	t.Setenv("SERVE_TEST_PORT", "abc")
+	cdr, out := newTestCommander("serve")
+	cdr.Register(commander.FromStruct("serve", "Start the server.", "serve [flags]", &portOptions{}), "")
+
+	var uerr *commander.UsageError
+	if err := cdr.Execute(); !errors.As(err, &uerr) || uerr.Kind != commander.ErrFlagParse {
+		t.Fatalf("want a flag parse error, got %v", err)
+	}
+	if !strings.Contains(out.String(), `invalid value "abc" for flag -port from $SERVE_TEST_PORT`) {
+		t.Fatalf("want the variable reported, got:\n%s", out.String())
+	}
+}
+
+func TestFromStructDefaults(t *testing.T) {
+	opts := &serveOptions{}
+	cmd := commander.FromStruct("serve", "Start the server.", "serve [flags]", opts)
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+
+	if opts.Addr != ":8080" || opts.Timeout != 5*time.Second || opts.Format != "json" {
+		t.Fatalf("want defaults applied, got %+v", opts)
+	}
+	if !reflect.DeepEqual(opts.Ports, []int{80, 443}) || opts.DB.Port != 5432 {
+		t.Fatalf("want slice and nested defaults applied, got %+v", opts)
+	}
+	if fs.Lookup("ignored") != nil {
+		t.Fatal("want fields tagged with - skipped")
+	}
+	if f := fs.Lookup("token"); f == nil || !strings.Contains(f.Usage, "[$SERVE_TEST_TOKEN]") {
+		t.Fatal("want the env variable named in the usage")
+	}
+	if err := fs.Parse([]string{"-format", "xml"}); err == nil {
+		t.Fatal("want an error for an invalid enum choice")
+	}
+}
+
+func TestFromStructCompletion(t *testing.T) {
+	cdr, _ := newTestCommander()
+	cdr.Register(commander.FromStruct("serve", "Start the server.", "serve [flags]", &serveOptions{}), "")
+
+	got := cdr.Complete([]string{"serve", "-format", "y"})
+	if strings.Join(got, " ") != "yaml" {
+		t.Fatalf("want enum choices completed, got %v", got)
+	}
+}
+
+func TestFromStructRerun(t *testing.T) {
+	opts := &serveOptions{MaxConn: 3}
+	cmd := commander.FromStruct("serve", "Start the server.", "serve [flags]", opts)
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+	if err := fs.Parse([]string{"-verbose", "-tag", "a", "-level", "warn", "-max-conn", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fs = flag.NewFlagSet("serve", flag.ContinueOnError)
+	cmd.SetFlags(fs)
+	if opts.Verbose || opts.Tags != nil || opts.Level.Value != "" || opts.MaxConn != 3 {
+		t.Fatalf("want the fields reset, got %+v", opts)
+	}
+	if opts.Addr != ":8080" {
+		t.Fatalf("want the defaults applied, got %q", opts.Addr)
+	}
+}
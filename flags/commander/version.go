@@ -0,0 +1,147 @@
+package commander
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"text/tabwriter"
+)
+
+// The version variables override the build information embedded by the
+// Go toolchain when set at link time, for example with:
+//
+//	go build -ldflags "-X github.com/lucasepe/toolbox/flags/commander.Version=v1.2.3"
+//
+// They are only defaults: a Commander with a BuildInfo does not use
+// them.
+var (
+	Version   string // the program version, such as v1.2.3
+	Revision  string // the VCS revision
+	BuildTime string // the build time, preferably in RFC 3339 format
+)
+
+// A BuildInfo describes the build of the running program.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Dirty     bool   `json:"dirty,omitempty"`
+	Time      string `json:"time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo returns the build information of the running program,
+// read from runtime/debug.ReadBuildInfo and overridden by the non-empty
+// Version, Revision and BuildTime variables.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Version = bi.Main.Version
+		info.GoVersion = bi.GoVersion
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.Time = s.Value
+			case "vcs.modified":
+				info.Dirty = s.Value == "true"
+			}
+		}
+	}
+
+	if Version != "" {
+		info.Version = Version
+	}
+	if Revision != "" {
+		info.Revision = Revision
+	}
+	if BuildTime != "" {
+		info.Time = BuildTime
+	}
+	if info.Version == "" {
+		info.Version = "(devel)"
+	}
+	return info
+}
+
+// buildInfo returns the BuildInfo of cdr, or else ReadBuildInfo().
+func (cdr *Commander) buildInfo() BuildInfo {
+	if cdr.BuildInfo != nil {
+		return *cdr.BuildInfo
+	}
+	return ReadBuildInfo()
+}
+
+// writeVersion prints the build information of the program.
+func (cdr *Commander) writeVersion(w io.Writer, asJSON bool) error {
+	info := cdr.buildInfo()
+	if asJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	fmt.Fprintf(w, "%s version %s\n", cdr.name, info.Version)
+	tw := tabwriter.NewWriter(w, 0, 3, 1, ' ', 0)
+	if info.Revision != "" {
+		rev := info.Revision
+		if info.Dirty {
+			rev += " (dirty)"
+		}
+		fmt.Fprintf(tw, "  revision:\t%s\n", rev)
+	}
+	if info.Time != "" {
+		fmt.Fprintf(tw, "  built:\t%s\n", info.Time)
+	}
+	fmt.Fprintf(tw, "  go:\t%s\n", info.GoVersion)
+	return tw.Flush()
+}
+
+// VersionFlag defines boolean top-level flags with the given names, such
+// as "v" and "version", which print the version of the program instead
+// of executing a command. It must be called before the top-level flags
+// are parsed.
+func (cdr *Commander) VersionFlag(names ...string) {
+	if cdr.version == nil {
+		cdr.version = new(bool)
+	}
+	for _, name := range names {
+		cdr.topFlags.BoolVar(cdr.version, name, false, "print the version and exit")
+	}
+}
+
+// A versionCmd is a Command implementing a "version" command for
+// a given Commander.
+type versionCmd struct {
+	cdr  *Commander
+	json bool
+}
+
+func (c *versionCmd) Name() string { return "version" }
+func (c *versionCmd) Synopsis() string {
+	return "Print the version of the program."
+}
+func (c *versionCmd) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.json, "json", false, "print the build information as JSON")
+}
+func (c *versionCmd) Usage() string {
+	return `version [-json]
+
+Prints the version, VCS revision, build time and Go version of the
+program.
+`
+}
+func (c *versionCmd) Args() ArgSpec { return NoArgs() }
+func (c *versionCmd) Execute(f *flag.FlagSet) error {
+	return c.cdr.writeVersion(c.cdr.Output, c.json)
+}
+
+// VersionCommand returns a Command which implements a "version"
+// subcommand, printing the build information of the program (see
+// BuildInfo and ReadBuildInfo).
+func (cdr *Commander) VersionCommand() Command {
+	return &versionCmd{cdr: cdr}
+}
@@ -0,0 +1,64 @@
+package commander_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags/commander"
+)
+
+func TestVersionCommand(t *testing.T) {
+	info := &commander.BuildInfo{Version: "v1.2.3", Revision: "abc123", Time: "2024-01-02T03:04:05Z", GoVersion: "go1.19"}
+
+	cdr, out := newTestCommander("version")
+	cdr.BuildInfo = info
+	cdr.Register(cdr.VersionCommand(), "")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"tool version v1.2.3", "abc123", "2024-01-02T03:04:05Z", "go"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("want %q in the output, got:\n%s", want, out.String())
+		}
+	}
+
+	cdr, out = newTestCommander("version", "-json")
+	cdr.BuildInfo = info
+	cdr.Register(cdr.VersionCommand(), "")
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	var got commander.BuildInfo
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != *info {
+		t.Fatalf("want %+v, got %+v", *info, got)
+	}
+}
+
+func TestVersionFlag(t *testing.T) {
+	fs := flag.NewFlagSet("tool", flag.ContinueOnError)
+	var out bytes.Buffer
+	cdr := commander.New(fs, "tool")
+	cdr.Output = &out
+	cdr.Error = &out
+	cdr.BuildInfo = &commander.BuildInfo{Version: "v1.2.3"}
+	cdr.VersionFlag("v", "version")
+	cmd := &testCmd{name: "print"}
+	cdr.Register(cmd, "")
+
+	fs.Parse([]string{"-v", "print"})
+	if err := cdr.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd.ran {
+		t.Fatal("want the command not to run")
+	}
+	if !strings.HasPrefix(out.String(), "tool version v1.2.3\n") {
+		t.Fatalf("want the version printed, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,56 @@
+package flags
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Count is a boolean `flag.Value` counting its instances, such as the
+// verbosity level given by `-v -v -v`, or `-vvv` with GNU style parsing.
+// `-v=N` sets the count to N, and `-v=false` resets it.
+type Count struct {
+	Value int
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Count) Help() string {
+	return "may be repeated to increase the count"
+}
+
+// IsBoolFlag lets the flag be given without a value.
+func (fv *Count) IsBoolFlag() bool {
+	return true
+}
+
+// Set is flag.Value.Set
+func (fv *Count) Set(v string) error {
+	if n, err := strconv.Atoi(v); err == nil {
+		if n < 0 {
+			return fmt.Errorf(`"%s" must be a boolean or a non-negative count`, v)
+		}
+		fv.Value = n
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf(`"%s" must be a boolean or a non-negative count`, v)
+	}
+	if b {
+		fv.Value++
+	} else {
+		fv.Value = 0
+	}
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Count) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *Count) String() string {
+	if fv == nil {
+		return ""
+	}
+	return strconv.Itoa(fv.Value)
+}
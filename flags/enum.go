@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/lucasepe/toolbox/text"
 )
 
 // Enum is a `flag.Value` for one-of-a-fixed-set string arguments.
@@ -25,6 +27,11 @@ func (fv *Enum) Help() string {
 	return fmt.Sprintf("one of %v", fv.Choices)
 }
 
+// ValidChoices is Chooser.ValidChoices
+func (fv *Enum) ValidChoices() []ChoiceInfo {
+	return namedChoices(fv.Choices)
+}
+
 // Set is flag.Value.Set
 func (fv *Enum) Set(v string) error {
 	fv.Text = v
@@ -38,7 +45,7 @@ func (fv *Enum) Set(v string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(fv.Choices, " "))
+	return choiceError(v, v, fv.Choices)
 }
 
 func (fv *Enum) String() string {
@@ -64,6 +71,11 @@ func (fv *Enums) Help() string {
 	return fmt.Sprintf("one of %v", fv.Choices)
 }
 
+// ValidChoices is Chooser.ValidChoices
+func (fv *Enums) ValidChoices() []ChoiceInfo {
+	return namedChoices(fv.Choices)
+}
+
 // Set is flag.Value.Set
 func (fv *Enums) Set(v string) error {
 	equal := strings.EqualFold
@@ -77,7 +89,7 @@ func (fv *Enums) Set(v string) error {
 			return nil
 		}
 	}
-	return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(fv.Choices, " "))
+	return choiceError(v, v, fv.Choices)
 }
 
 func (fv *Enums) String() string {
@@ -111,6 +123,11 @@ func (fv *EnumsCSV) Help() string {
 	return fmt.Sprintf("%q-separated list of values from %v", separator, fv.Choices)
 }
 
+// ValidChoices is Chooser.ValidChoices
+func (fv *EnumsCSV) ValidChoices() []ChoiceInfo {
+	return namedChoices(fv.Choices)
+}
+
 // Set is flag.Value.Set
 func (fv *EnumsCSV) Set(v string) error {
 	equal := strings.EqualFold
@@ -137,7 +154,7 @@ func (fv *EnumsCSV) Set(v string) error {
 			}
 		}
 		if !ok {
-			return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(fv.Choices, " "))
+			return choiceError(v, part, fv.Choices)
 		}
 		fv.Values = append(fv.Values, value)
 		fv.Texts = append(fv.Texts, part)
@@ -178,6 +195,11 @@ func (fv *EnumSet) Values() (out []string) {
 	return
 }
 
+// ValidChoices is Chooser.ValidChoices
+func (fv *EnumSet) ValidChoices() []ChoiceInfo {
+	return namedChoices(fv.Choices)
+}
+
 // Set is flag.Value.Set
 func (fv *EnumSet) Set(v string) error {
 	equal := strings.EqualFold
@@ -193,7 +215,7 @@ func (fv *EnumSet) Set(v string) error {
 		}
 	}
 	if !ok {
-		return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(fv.Choices, " "))
+		return choiceError(v, v, fv.Choices)
 	}
 	if fv.Value == nil {
 		fv.Value = make(map[string]bool)
@@ -244,6 +266,11 @@ func (fv *EnumSetCSV) Values() (out []string) {
 	return
 }
 
+// ValidChoices is Chooser.ValidChoices
+func (fv *EnumSetCSV) ValidChoices() []ChoiceInfo {
+	return namedChoices(fv.Choices)
+}
+
 // Set is flag.Value.Set
 func (fv *EnumSetCSV) Set(v string) error {
 	equal := strings.EqualFold
@@ -270,7 +297,7 @@ func (fv *EnumSetCSV) Set(v string) error {
 			}
 		}
 		if !ok {
-			return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(fv.Choices, " "))
+			return choiceError(v, part, fv.Choices)
 		}
 		fv.Value[value] = true
 		fv.Texts = append(fv.Texts, part)
@@ -281,3 +308,21 @@ func (fv *EnumSetCSV) Set(v string) error {
 func (fv *EnumSetCSV) String() string {
 	return strings.Join(fv.Values(), ",")
 }
+
+// namedChoices returns the descriptions of the given choices.
+func namedChoices(choices []string) []ChoiceInfo {
+	infos := make([]ChoiceInfo, len(choices))
+	for i, c := range choices {
+		infos[i] = ChoiceInfo{Name: c}
+	}
+	return infos
+}
+
+// choiceError reports that v is not a valid choice, suggesting the
+// closest choices to the offending part of v.
+func choiceError(v, part string, choices []string) error {
+	if s := text.Suggest(part, choices, 0); len(s) > 0 {
+		return fmt.Errorf(`"%s" must be one of [%s], did you mean "%s"?`, v, strings.Join(choices, " "), s[0])
+	}
+	return fmt.Errorf(`"%s" must be one of [%s]`, v, strings.Join(choices, " "))
+}
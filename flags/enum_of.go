@@ -0,0 +1,251 @@
+package flags
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChoiceInfo describes a valid choice of an enum flag value.
+type ChoiceInfo struct {
+	Name        string
+	Description string
+	Aliases     []string
+}
+
+// Chooser is implemented by the `flag.Value`s accepting a fixed set of
+// choices, such as the enum types of this package. Help and completion
+// generators use it to list the valid choices.
+type Chooser interface {
+	ValidChoices() []ChoiceInfo
+}
+
+// Choice is a valid choice of an `EnumOf` or `EnumsOf`: the `Name` given
+// on the command line, or one of its `Aliases`, selects the `Value`.
+// The `Description` is shown in help messages.
+type Choice[T any] struct {
+	Name        string
+	Value       T
+	Description string
+	Aliases     []string
+}
+
+// EnumOf is a `flag.Value` for one-of-a-fixed-set arguments of type T.
+// The value of the `Choices` field defines the valid choices.
+// If `CaseSensitive` is set to `true` (default `false`), the comparison is case-sensitive.
+// To set a default value, call `Set` before defining the flag.
+type EnumOf[T any] struct {
+	Choices       []Choice[T]
+	CaseSensitive bool
+
+	Value T      // the value of the chosen choice
+	Name  string // the name of the chosen choice
+	Text  string // the text given on the command line
+}
+
+// NewEnumOf returns an `EnumOf` whose choices are the keys of choices,
+// sorted, selecting their values.
+func NewEnumOf[T any](choices map[string]T) *EnumOf[T] {
+	return &EnumOf[T]{Choices: choicesOf(choices)}
+}
+
+// EnumOfStringers returns an `EnumOf` whose choices are the given values,
+// named by their `String` method.
+func EnumOfStringers[T fmt.Stringer](values ...T) *EnumOf[T] {
+	return &EnumOf[T]{Choices: stringerChoices(values)}
+}
+
+// Describe sets the description and adds aliases to the named choice.
+// It returns fv, so that calls can be chained.
+func (fv *EnumOf[T]) Describe(name, description string, aliases ...string) *EnumOf[T] {
+	describeChoice(fv.Choices, name, description, aliases)
+	return fv
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *EnumOf[T]) Help() string {
+	if fv.CaseSensitive {
+		return fmt.Sprintf("one of %v (case-sensitive)", choiceNames(fv.Choices))
+	}
+	return fmt.Sprintf("one of %v", choiceNames(fv.Choices))
+}
+
+// ValidChoices is Chooser.ValidChoices
+func (fv *EnumOf[T]) ValidChoices() []ChoiceInfo {
+	return choiceInfos(fv.Choices)
+}
+
+// Set is flag.Value.Set
+func (fv *EnumOf[T]) Set(v string) error {
+	c, ok := findChoice(fv.Choices, v, fv.CaseSensitive)
+	if !ok {
+		return choiceError(v, v, choiceNames(fv.Choices))
+	}
+	fv.Value, fv.Name, fv.Text = c.Value, c.Name, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *EnumOf[T]) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *EnumOf[T]) String() string {
+	if fv == nil {
+		return ""
+	}
+	return fv.Name
+}
+
+// EnumsOf is a `flag.Value` for separator-separated enum arguments of
+// type T. The value of the `Choices` field defines the valid choices.
+// If `Accumulate` is set, the values of all instances of the flag are accumulated.
+// The `Separator` field is used instead of the comma when set.
+// If `CaseSensitive` is set to `true` (default `false`), the comparison is case-sensitive.
+type EnumsOf[T any] struct {
+	Choices       []Choice[T]
+	Separator     string
+	Accumulate    bool
+	CaseSensitive bool
+
+	Values []T      // the values of the chosen choices
+	Names  []string // the names of the chosen choices
+	Texts  []string // the texts given on the command line
+}
+
+// NewEnumsOf returns an `EnumsOf` whose choices are the keys of choices,
+// sorted, selecting their values.
+func NewEnumsOf[T any](choices map[string]T) *EnumsOf[T] {
+	return &EnumsOf[T]{Choices: choicesOf(choices)}
+}
+
+// EnumsOfStringers returns an `EnumsOf` whose choices are the given
+// values, named by their `String` method.
+func EnumsOfStringers[T fmt.Stringer](values ...T) *EnumsOf[T] {
+	return &EnumsOf[T]{Choices: stringerChoices(values)}
+}
+
+// Describe sets the description and adds aliases to the named choice.
+// It returns fv, so that calls can be chained.
+func (fv *EnumsOf[T]) Describe(name, description string, aliases ...string) *EnumsOf[T] {
+	describeChoice(fv.Choices, name, description, aliases)
+	return fv
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *EnumsOf[T]) Help() string {
+	separator := ","
+	if fv.Separator != "" {
+		separator = fv.Separator
+	}
+	if fv.CaseSensitive {
+		return fmt.Sprintf("%q-separated list of values from %v (case-sensitive)", separator, choiceNames(fv.Choices))
+	}
+	return fmt.Sprintf("%q-separated list of values from %v", separator, choiceNames(fv.Choices))
+}
+
+// ValidChoices is Chooser.ValidChoices
+func (fv *EnumsOf[T]) ValidChoices() []ChoiceInfo {
+	return choiceInfos(fv.Choices)
+}
+
+// Set is flag.Value.Set
+func (fv *EnumsOf[T]) Set(v string) error {
+	separator := fv.Separator
+	if separator == "" {
+		separator = ","
+	}
+	if !fv.Accumulate {
+		fv.Values, fv.Names, fv.Texts = fv.Values[:0], fv.Names[:0], fv.Texts[:0]
+	}
+	for _, part := range strings.Split(v, separator) {
+		part = strings.TrimSpace(part)
+		c, ok := findChoice(fv.Choices, part, fv.CaseSensitive)
+		if !ok {
+			return choiceError(v, part, choiceNames(fv.Choices))
+		}
+		fv.Values = append(fv.Values, c.Value)
+		fv.Names = append(fv.Names, c.Name)
+		fv.Texts = append(fv.Texts, part)
+	}
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *EnumsOf[T]) Get() interface{} {
+	return fv.Values
+}
+
+func (fv *EnumsOf[T]) String() string {
+	if fv == nil {
+		return ""
+	}
+	return strings.Join(fv.Names, ",")
+}
+
+// choicesOf returns the choices named by the keys of m, sorted by name.
+func choicesOf[T any](m map[string]T) []Choice[T] {
+	choices := make([]Choice[T], 0, len(m))
+	for name, v := range m {
+		choices = append(choices, Choice[T]{Name: name, Value: v})
+	}
+	sort.Slice(choices, func(i, j int) bool { return choices[i].Name < choices[j].Name })
+	return choices
+}
+
+// stringerChoices returns the choices of values, named by their String.
+func stringerChoices[T fmt.Stringer](values []T) []Choice[T] {
+	choices := make([]Choice[T], 0, len(values))
+	for _, v := range values {
+		choices = append(choices, Choice[T]{Name: v.String(), Value: v})
+	}
+	return choices
+}
+
+// describeChoice sets the description and adds aliases to the named
+// choice, panicking if there is none.
+func describeChoice[T any](choices []Choice[T], name, description string, aliases []string) {
+	for i := range choices {
+		if choices[i].Name == name {
+			choices[i].Description = description
+			choices[i].Aliases = append(choices[i].Aliases, aliases...)
+			return
+		}
+	}
+	panic(fmt.Sprintf("flags: no choice named %q", name))
+}
+
+// findChoice returns the choice named v, or having v as an alias.
+func findChoice[T any](choices []Choice[T], v string, caseSensitive bool) (Choice[T], bool) {
+	equal := strings.EqualFold
+	if caseSensitive {
+		equal = func(a, b string) bool { return a == b }
+	}
+	for _, c := range choices {
+		if equal(c.Name, v) {
+			return c, true
+		}
+		for _, a := range c.Aliases {
+			if equal(a, v) {
+				return c, true
+			}
+		}
+	}
+	return Choice[T]{}, false
+}
+
+func choiceNames[T any](choices []Choice[T]) []string {
+	names := make([]string, len(choices))
+	for i, c := range choices {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func choiceInfos[T any](choices []Choice[T]) []ChoiceInfo {
+	infos := make([]ChoiceInfo, len(choices))
+	for i, c := range choices {
+		infos[i] = ChoiceInfo{Name: c.Name, Description: c.Description, Aliases: c.Aliases}
+	}
+	return infos
+}
@@ -0,0 +1,80 @@
+package flags_test
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+)
+
+type level int
+
+const (
+	debug level = iota
+	info
+	warn
+)
+
+func (l level) String() string {
+	return [...]string{"debug", "info", "warn"}[l]
+}
+
+func ExampleEnumOf() {
+	type mode int
+	const (
+		fast mode = iota + 1
+		exact
+	)
+
+	m := flags.NewEnumOf(map[string]mode{"fast": fast, "exact": exact}).
+		Describe("fast", "skip the checks", "f")
+	lvl := flags.EnumOfStringers(debug, info, warn)
+	lvl.Set("info")
+
+	var fs flag.FlagSet
+	fs.Var(m, "mode", "set a mode")
+	fs.Var(lvl, "level", "set the log level")
+
+	fs.Parse([]string{"-mode", "F"})
+	fmt.Println(m.Value == fast, m.Name, lvl.Value)
+	// Output:
+	// true fast info
+}
+
+func TestEnumsOf(t *testing.T) {
+	levels := flags.EnumsOfStringers(debug, info, warn)
+	levels.Accumulate = true
+
+	var fs flag.FlagSet
+	fs.Var(levels, "levels", "enable log levels")
+	if err := fs.Parse([]string{"-levels", "debug,WARN", "-levels", "info"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []level{debug, warn, info}; !reflect.DeepEqual(levels.Values, want) {
+		t.Fatalf("want %v, got %v", want, levels.Values)
+	}
+	if got := fs.Lookup("levels").Value.String(); got != "debug,warn,info" {
+		t.Fatalf("want the canonical names, got %q", got)
+	}
+
+	err := levels.Set("debug,inof")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "info"?`) {
+		t.Fatalf("want a suggestion, got %v", err)
+	}
+}
+
+func TestValidChoices(t *testing.T) {
+	for _, c := range []flags.Chooser{
+		&flags.Enum{Choices: []string{"a", "b"}},
+		&flags.EnumSetCSV{Choices: []string{"a", "b"}},
+		flags.NewEnumOf(map[string]int{"b": 2, "a": 1}),
+	} {
+		infos := c.ValidChoices()
+		if len(infos) != 2 || infos[0].Name != "a" || infos[1].Name != "b" {
+			t.Errorf("%T: unexpected choices %v", c, infos)
+		}
+	}
+}
@@ -290,3 +290,15 @@ func TestEnumSetCSVFail(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestEnumSuggestion(t *testing.T) {
+	fv := flags.EnumsCSV{Choices: []string{"fast", "exact"}}
+	err := fv.Set("exact,fsat")
+	if err == nil {
+		t.Fatal("want error")
+	}
+	want := `"exact,fsat" must be one of [fast exact], did you mean "fast"?`
+	if err.Error() != want {
+		t.Fatalf("want %s, got %s", want, err)
+	}
+}
@@ -0,0 +1,100 @@
+package flags
+
+import (
+	"flag"
+
+	"github.com/lucasepe/toolbox/logutils"
+)
+
+// VerbosityLevel returns the level of levels, in increasing order of
+// severity, verbosity levels below base. It stops at the first level,
+// and starts from the last one if base is not in levels.
+func VerbosityLevel(levels []logutils.LogLevel, base logutils.LogLevel, verbosity int) logutils.LogLevel {
+	if len(levels) == 0 {
+		return base
+	}
+	i := len(levels) - 1
+	for j, level := range levels {
+		if level == base {
+			i = j
+		}
+	}
+	if i -= verbosity; i < 0 {
+		i = 0
+	}
+	return levels[i]
+}
+
+// SetVerbosity lowers the MinLevel of filter by verbosity levels.
+func SetVerbosity(filter *logutils.LevelFilter, verbosity int) {
+	filter.SetMinLevel(VerbosityLevel(filter.Levels, filter.MinLevel, verbosity))
+}
+
+// LogLevel returns an `Enum` whose choices are the levels of filter, set
+// to its MinLevel, for a flag selecting the minimum log level.
+func LogLevel(filter *logutils.LevelFilter) *Enum {
+	fv := &Enum{Choices: make([]string, len(filter.Levels))}
+	for i, level := range filter.Levels {
+		fv.Choices[i] = string(level)
+	}
+	fv.Value, fv.Text = string(filter.MinLevel), string(filter.MinLevel)
+	return fv
+}
+
+// LogFlags defines in fs the flags configuring filter the same way in all
+// tools: `-log-level`, setting its MinLevel to one of its Levels, and
+// `-v`, a `Count` lowering the MinLevel by one level per instance. The
+// filter is updated as the flags are parsed, in any order.
+func LogFlags(fs *flag.FlagSet, filter *logutils.LevelFilter) {
+	lf := &logFlags{filter: filter, base: filter.MinLevel, level: LogLevel(filter)}
+	fs.Var(&logLevelValue{lf.level, lf}, "log-level",
+		"`level` the minimum level of the log messages, "+lf.level.Help())
+	fs.Var(&verbosityValue{&lf.verbosity, lf}, "v",
+		"increase the log verbosity, "+lf.verbosity.Help())
+}
+
+// logFlags is the state shared by the flags defined by LogFlags.
+type logFlags struct {
+	filter    *logutils.LevelFilter
+	base      logutils.LogLevel
+	level     *Enum
+	verbosity Count
+}
+
+func (lf *logFlags) apply() {
+	lf.filter.SetMinLevel(VerbosityLevel(lf.filter.Levels, lf.base, lf.verbosity.Value))
+}
+
+type logLevelValue struct {
+	*Enum
+	lf *logFlags
+}
+
+func (v *logLevelValue) Set(s string) error {
+	if err := v.Enum.Set(s); err != nil {
+		return err
+	}
+	v.lf.base = logutils.LogLevel(v.Value)
+	v.lf.apply()
+	return nil
+}
+
+func (v *logLevelValue) String() string {
+	if v.Enum == nil {
+		return ""
+	}
+	return v.Enum.String()
+}
+
+type verbosityValue struct {
+	*Count
+	lf *logFlags
+}
+
+func (v *verbosityValue) Set(s string) error {
+	if err := v.Count.Set(s); err != nil {
+		return err
+	}
+	v.lf.apply()
+	return nil
+}
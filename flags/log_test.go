@@ -0,0 +1,96 @@
+package flags_test
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/logutils"
+)
+
+func ExampleLogFlags() {
+	var buf bytes.Buffer
+	filter := &logutils.LevelFilter{
+		Levels:   []logutils.LogLevel{"DEBUG", "INFO", "WARN", "ERROR"},
+		MinLevel: "WARN",
+		Writer:   &buf,
+	}
+
+	var fs flag.FlagSet
+	flags.LogFlags(&fs, filter)
+	fs.Parse([]string{"-v"})
+
+	logger := log.New(filter, "", 0)
+	logger.Print("[DEBUG] hidden")
+	logger.Print("[INFO] shown")
+	fmt.Print(buf.String())
+
+	// Output:
+	// [INFO] shown
+}
+
+func TestCount(t *testing.T) {
+	var fv flags.Count
+	var fs flag.FlagSet
+	fs.Var(&fv, "v", "")
+
+	if err := fs.Parse([]string{"-v", "-v", "-v"}); err != nil || fv.Value != 3 {
+		t.Fatalf("want 3, got %d (%v)", fv.Value, err)
+	}
+	if err := fs.Parse([]string{"-v=false", "-v"}); err != nil || fv.Value != 1 {
+		t.Fatalf("want the count reset, got %d (%v)", fv.Value, err)
+	}
+	if err := fs.Parse([]string{"-v=5"}); err != nil || fv.Value != 5 {
+		t.Fatalf("want 5, got %d (%v)", fv.Value, err)
+	}
+	if err := fs.Parse([]string{"-v=1"}); err != nil || fv.Value != 1 {
+		t.Fatalf("want the count set to 1, got %d (%v)", fv.Value, err)
+	}
+	if err := fv.Set("-1"); err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestVerbosityLevel(t *testing.T) {
+	levels := []logutils.LogLevel{"DEBUG", "INFO", "WARN", "ERROR"}
+	for _, tt := range []struct {
+		base      logutils.LogLevel
+		verbosity int
+		want      logutils.LogLevel
+	}{
+		{"WARN", 0, "WARN"},
+		{"WARN", 1, "INFO"},
+		{"WARN", 5, "DEBUG"},
+		{"", 1, "WARN"},
+	} {
+		if got := flags.VerbosityLevel(levels, tt.base, tt.verbosity); got != tt.want {
+			t.Errorf("%s -%d: want %s, got %s", tt.base, tt.verbosity, tt.want, got)
+		}
+	}
+}
+
+func TestLogFlags(t *testing.T) {
+	filter := &logutils.LevelFilter{
+		Levels:   []logutils.LogLevel{"DEBUG", "INFO", "WARN", "ERROR"},
+		MinLevel: "ERROR",
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	flags.LogFlags(fs, filter)
+
+	if err := fs.Parse([]string{"-v", "-log-level", "warn"}); err != nil {
+		t.Fatal(err)
+	}
+	if filter.MinLevel != "INFO" {
+		t.Fatalf("want INFO, got %s", filter.MinLevel)
+	}
+	if got := fs.Lookup("log-level").DefValue; got != "ERROR" {
+		t.Fatalf("want the default level ERROR, got %s", got)
+	}
+	if err := fs.Parse([]string{"-log-level", "trace"}); err == nil {
+		t.Fatal("want error")
+	}
+}
@@ -0,0 +1,77 @@
+package flags
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map is a `flag.Value` for `key=value` arguments. The pairs of all
+// instances of the flag are accumulated, a later value replacing an
+// earlier one for the same key. If `Separator` is set, each instance may
+// hold several pairs separated by it, such as `a=1,b=2`.
+type Map struct {
+	Separator string
+
+	Value map[string]string
+	Texts []string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Map) Help() string {
+	if fv.Separator != "" {
+		return fmt.Sprintf("%q-separated list of key=value pairs", fv.Separator)
+	}
+	return "a key=value pair"
+}
+
+// Set is flag.Value.Set
+func (fv *Map) Set(v string) error {
+	pairs := []string{v}
+	if fv.Separator != "" {
+		pairs = strings.Split(v, fv.Separator)
+	}
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf(`"%s" must be of the form key=value`, pair)
+		}
+		m[key] = value
+	}
+	if fv.Value == nil {
+		fv.Value = make(map[string]string, len(m))
+	}
+	for key, value := range m {
+		fv.Value[key] = value
+	}
+	fv.Texts = append(fv.Texts, v)
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Map) Get() interface{} {
+	return fv.Value
+}
+
+// Keys returns the sorted keys of the map.
+func (fv *Map) Keys() []string {
+	keys := make([]string, 0, len(fv.Value))
+	for key := range fv.Value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (fv *Map) String() string {
+	if fv == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(fv.Value))
+	for _, key := range fv.Keys() {
+		pairs = append(pairs, key+"="+fv.Value[key])
+	}
+	return strings.Join(pairs, ",")
+}
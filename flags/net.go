@@ -0,0 +1,125 @@
+package flags
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// URL is a `flag.Value` for absolute URL arguments.
+// If `Schemes` is set, the scheme of the URL must be one of them.
+type URL struct {
+	Schemes []string
+
+	Value *url.URL
+	Text  string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *URL) Help() string {
+	if len(fv.Schemes) > 0 {
+		return fmt.Sprintf("a URL with a scheme from %v", fv.Schemes)
+	}
+	return "an absolute URL"
+}
+
+// Set is flag.Value.Set
+func (fv *URL) Set(v string) error {
+	u, err := url.Parse(v)
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf(`"%s" is not an absolute URL`, v)
+	}
+	if len(fv.Schemes) > 0 {
+		var ok bool
+		for _, s := range fv.Schemes {
+			ok = ok || strings.EqualFold(s, u.Scheme)
+		}
+		if !ok {
+			return fmt.Errorf(`"%s" must have a scheme from [%s]`, v, strings.Join(fv.Schemes, " "))
+		}
+	}
+	fv.Value, fv.Text = u, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *URL) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *URL) String() string {
+	if fv == nil || fv.Value == nil {
+		return ""
+	}
+	return fv.Value.String()
+}
+
+// IP is a `flag.Value` for IPv4 or IPv6 address arguments.
+type IP struct {
+	Value net.IP
+	Text  string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *IP) Help() string {
+	return "an IPv4 or IPv6 address"
+}
+
+// Set is flag.Value.Set
+func (fv *IP) Set(v string) error {
+	ip := net.ParseIP(strings.TrimSpace(v))
+	if ip == nil {
+		return fmt.Errorf(`"%s" is not a valid IP address`, v)
+	}
+	fv.Value, fv.Text = ip, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *IP) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *IP) String() string {
+	if fv == nil || fv.Value == nil {
+		return ""
+	}
+	return fv.Value.String()
+}
+
+// CIDR is a `flag.Value` for IP address and prefix length arguments in
+// CIDR notation, such as `192.0.2.1/24`. `IP` is the address given and
+// `Value` the network it belongs to.
+type CIDR struct {
+	IP    net.IP
+	Value *net.IPNet
+	Text  string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *CIDR) Help() string {
+	return "an IP address and prefix length, such as 192.0.2.0/24"
+}
+
+// Set is flag.Value.Set
+func (fv *CIDR) Set(v string) error {
+	ip, network, err := net.ParseCIDR(strings.TrimSpace(v))
+	if err != nil {
+		return fmt.Errorf(`"%s" is not a valid CIDR notation address`, v)
+	}
+	fv.IP, fv.Value, fv.Text = ip, network, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *CIDR) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *CIDR) String() string {
+	if fv == nil || fv.Value == nil {
+		return ""
+	}
+	return (&net.IPNet{IP: fv.IP, Mask: fv.Value.Mask}).String()
+}
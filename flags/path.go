@@ -0,0 +1,173 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File is a `flag.Value` for file path arguments.
+// If `MustExist` is set, the file must exist. If `Readable` or `Writable`
+// is set, the file must be readable or writable when it exists; a file
+// that does not exist yet must be creatable in its directory to be
+// writable. A path naming a directory is always rejected.
+type File struct {
+	MustExist bool
+	Readable  bool
+	Writable  bool
+
+	Value string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *File) Help() string {
+	return "a file path" + pathRequirements(fv.MustExist, fv.Readable, fv.Writable)
+}
+
+// Set is flag.Value.Set
+func (fv *File) Set(v string) error {
+	if err := checkPath(v, false, fv.MustExist, fv.Readable, fv.Writable); err != nil {
+		return err
+	}
+	fv.Value = v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *File) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *File) String() string {
+	if fv == nil {
+		return ""
+	}
+	return fv.Value
+}
+
+// Dir is a `flag.Value` for directory path arguments.
+// If `MustExist` is set, the directory must exist. If `Readable` or
+// `Writable` is set, the directory must be listable or allow creating
+// files in it when it exists; a directory that does not exist yet must be
+// creatable in its parent to be writable. A path naming a file is always
+// rejected.
+type Dir struct {
+	MustExist bool
+	Readable  bool
+	Writable  bool
+
+	Value string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Dir) Help() string {
+	return "a directory path" + pathRequirements(fv.MustExist, fv.Readable, fv.Writable)
+}
+
+// Set is flag.Value.Set
+func (fv *Dir) Set(v string) error {
+	if err := checkPath(v, true, fv.MustExist, fv.Readable, fv.Writable); err != nil {
+		return err
+	}
+	fv.Value = v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Dir) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *Dir) String() string {
+	if fv == nil {
+		return ""
+	}
+	return fv.Value
+}
+
+func pathRequirements(mustExist, readable, writable bool) string {
+	var reqs []string
+	if mustExist {
+		reqs = append(reqs, "existing")
+	}
+	if readable {
+		reqs = append(reqs, "readable")
+	}
+	if writable {
+		reqs = append(reqs, "writable")
+	}
+	if len(reqs) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(reqs, ", ") + ")"
+}
+
+// checkPath checks the requirements on the file, or directory if dir is
+// set, at path.
+func checkPath(path string, dir, mustExist, readable, writable bool) error {
+	if path == "" {
+		return errors.New("the path is empty")
+	}
+	fi, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if mustExist {
+			return fmt.Errorf(`"%s" does not exist`, path)
+		}
+		if writable && !canCreate(filepath.Dir(path)) {
+			return fmt.Errorf(`"%s" cannot be created`, path)
+		}
+		return nil
+	case err != nil:
+		return err
+	case dir && !fi.IsDir():
+		return fmt.Errorf(`"%s" is not a directory`, path)
+	case !dir && fi.IsDir():
+		return fmt.Errorf(`"%s" is a directory`, path)
+	}
+
+	if readable {
+		f, err := os.Open(path)
+		if err == nil && dir {
+			_, err = f.Readdirnames(1)
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+		}
+		if f != nil {
+			f.Close()
+		}
+		if err != nil {
+			return fmt.Errorf(`"%s" is not readable`, path)
+		}
+	}
+	if writable {
+		var ok bool
+		if dir {
+			ok = canCreate(path)
+		} else if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+			ok = true
+			f.Close()
+		}
+		if !ok {
+			return fmt.Errorf(`"%s" is not writable`, path)
+		}
+	}
+	return nil
+}
+
+// canCreate reports whether files can be created in the directory dir,
+// by creating and removing a temporary one.
+func canCreate(dir string) bool {
+	f, err := os.CreateTemp(dir, ".flags-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
@@ -0,0 +1,99 @@
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Number is the set of the numeric types of a `Range`.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Range is a `flag.Value` for numeric arguments of type T within the
+// inclusive bounds `Min` and `Max`. If both bounds are zero, any value of
+// type T is accepted. Integers can be given in any base accepted by
+// `strconv.ParseInt`, such as `0x1f`.
+type Range[T Number] struct {
+	Min, Max T
+
+	Value T
+	Text  string
+}
+
+// NewRange returns a `Range` with the given bounds, and value set to min.
+func NewRange[T Number](min, max T) *Range[T] {
+	return &Range[T]{Min: min, Max: max, Value: min}
+}
+
+func (fv *Range[T]) bounded() bool {
+	return fv.Min != 0 || fv.Max != 0
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Range[T]) Help() string {
+	if fv.bounded() {
+		return fmt.Sprintf("a number between %v and %v", fv.Min, fv.Max)
+	}
+	return "a number"
+}
+
+// Set is flag.Value.Set
+func (fv *Range[T]) Set(v string) error {
+	n, err := parseNumber[T](v)
+	if err != nil {
+		return err
+	}
+	if fv.bounded() && (n < fv.Min || n > fv.Max) {
+		return fmt.Errorf(`"%s" must be between %v and %v`, v, fv.Min, fv.Max)
+	}
+	fv.Value, fv.Text = n, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Range[T]) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *Range[T]) String() string {
+	if fv == nil {
+		return ""
+	}
+	return fmt.Sprint(fv.Value)
+}
+
+// parseNumber parses v as a number of type T.
+func parseNumber[T Number](v string) (T, error) {
+	var n T
+	rv := reflect.ValueOf(&n).Elem()
+	var err error
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(v, 0, rv.Type().Bits()); err == nil {
+			rv.SetInt(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(v, rv.Type().Bits()); err == nil {
+			rv.SetFloat(f)
+		}
+	default:
+		var u uint64
+		if u, err = strconv.ParseUint(v, 0, rv.Type().Bits()); err == nil {
+			rv.SetUint(u)
+		}
+	}
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		return n, fmt.Errorf(`"%s" is out of range`, v)
+	case err != nil:
+		return n, fmt.Errorf(`"%s" is not a valid number`, v)
+	}
+	return n, nil
+}
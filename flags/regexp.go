@@ -0,0 +1,40 @@
+package flags
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Regexp is a `flag.Value` for regular expression arguments, in the
+// syntax of the `regexp` package.
+type Regexp struct {
+	Value *regexp.Regexp
+	Text  string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Regexp) Help() string {
+	return "a regular expression"
+}
+
+// Set is flag.Value.Set
+func (fv *Regexp) Set(v string) error {
+	re, err := regexp.Compile(v)
+	if err != nil {
+		return fmt.Errorf(`"%s" is not a valid regular expression: %w`, v, err)
+	}
+	fv.Value, fv.Text = re, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Regexp) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *Regexp) String() string {
+	if fv == nil || fv.Value == nil {
+		return ""
+	}
+	return fv.Value.String()
+}
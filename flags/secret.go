@@ -0,0 +1,180 @@
+package flags
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lucasepe/toolbox/secret"
+	"golang.org/x/term"
+)
+
+// Redacted is the placeholder a `Secret` shows instead of its value.
+const Redacted = "[REDACTED]"
+
+// Secret is a `flag.Value` for secret arguments, such as passwords and
+// tokens, which never shows its value: `String` returns `Redacted` once
+// the secret is set, so that neither help messages nor the output of
+// `flag.PrintDefaults` leak it. The argument names where the secret is
+// read from:
+//
+//	env:NAME        the environment variable NAME
+//	file:PATH       the contents of the file at PATH
+//	-               the standard input
+//	prompt:[LABEL]  a no-echo prompt on the terminal, showing LABEL
+//	anything else   the secret itself
+//
+// A single trailing newline is removed from the files and the standard
+// input. If `Key` is set, inputs encrypted with `secret.Encrypt`, raw or
+// base64-encoded, are decrypted with it; other inputs are used as is.
+// `Stdin` and `LookupEnv` default to `os.Stdin` and `os.LookupEnv`.
+type Secret struct {
+	Key       string
+	Stdin     io.Reader
+	LookupEnv func(key string) (string, bool)
+
+	value  []byte
+	source string
+	set    bool
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Secret) Help() string {
+	return "a secret, read from env:NAME, file:PATH, - (stdin) or prompt:"
+}
+
+// Set is flag.Value.Set
+func (fv *Secret) Set(v string) error {
+	data, source, err := fv.read(v)
+	if err != nil {
+		return err
+	}
+	if fv.Key != "" {
+		data = decryptSecret(fv.Key, data)
+	}
+	fv.Clear()
+	fv.value, fv.source, fv.set = data, source, true
+	return nil
+}
+
+func (fv *Secret) read(v string) ([]byte, string, error) {
+	switch {
+	case strings.HasPrefix(v, "env:"):
+		name := strings.TrimPrefix(v, "env:")
+		lookupEnv := fv.LookupEnv
+		if lookupEnv == nil {
+			lookupEnv = os.LookupEnv
+		}
+		s, ok := lookupEnv(name)
+		if !ok {
+			return nil, "", fmt.Errorf("the environment variable %s is not set", name)
+		}
+		return []byte(s), v, nil
+	case strings.HasPrefix(v, "file:"):
+		data, err := os.ReadFile(strings.TrimPrefix(v, "file:"))
+		if err != nil {
+			return nil, "", err
+		}
+		return trimNewline(data), v, nil
+	case v == "-":
+		data, err := io.ReadAll(fv.stdin())
+		if err != nil {
+			return nil, "", err
+		}
+		return trimNewline(data), "stdin", nil
+	case strings.HasPrefix(v, "prompt:"):
+		data, err := fv.prompt(strings.TrimPrefix(v, "prompt:"))
+		return data, "prompt", err
+	}
+	return []byte(v), "literal", nil
+}
+
+func (fv *Secret) stdin() io.Reader {
+	if fv.Stdin != nil {
+		return fv.Stdin
+	}
+	return os.Stdin
+}
+
+// prompt reads the secret from the terminal without echoing it.
+func (fv *Secret) prompt(label string) ([]byte, error) {
+	f, ok := fv.stdin().(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return nil, errors.New("cannot prompt for the secret: the standard input is not a terminal")
+	}
+	if label == "" {
+		label = "Secret"
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	defer fmt.Fprintln(os.Stderr)
+	return term.ReadPassword(int(f.Fd()))
+}
+
+// Value returns the secret.
+func (fv *Secret) Value() string {
+	return string(fv.value)
+}
+
+// Bytes returns the secret. The returned slice is overwritten by Clear.
+func (fv *Secret) Bytes() []byte {
+	return fv.value
+}
+
+// IsSet reports whether the secret was set.
+func (fv *Secret) IsSet() bool {
+	return fv.set
+}
+
+// Source describes where the secret was read from, such as `env:TOKEN`
+// or `stdin`, without revealing it.
+func (fv *Secret) Source() string {
+	return fv.source
+}
+
+// Clear overwrites the secret in memory and unsets it.
+func (fv *Secret) Clear() {
+	for i := range fv.value {
+		fv.value[i] = 0
+	}
+	fv.value, fv.source, fv.set = nil, "", false
+}
+
+// String returns Redacted, or the empty string if the secret is not set.
+// Its value receiver also redacts the secret when a Secret is formatted
+// by value.
+func (fv Secret) String() string {
+	if !fv.set {
+		return ""
+	}
+	return Redacted
+}
+
+// GoString redacts the secret in the output of the %#v verb.
+func (fv Secret) GoString() string {
+	return fmt.Sprintf("flags.Secret{%s}", fv.String())
+}
+
+// decryptSecret decrypts data, raw or base64-encoded, with key, or
+// returns it unchanged if it was not encrypted with key.
+func decryptSecret(key string, data []byte) []byte {
+	if dec, err := secret.Decrypt(key, data); err == nil {
+		return dec
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return data
+	}
+	if dec, err := secret.Decrypt(key, raw); err == nil {
+		return dec
+	}
+	return data
+}
+
+func trimNewline(data []byte) []byte {
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return bytes.TrimSuffix(data, []byte("\r"))
+}
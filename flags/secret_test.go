@@ -0,0 +1,103 @@
+package flags_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasepe/toolbox/flags"
+	"github.com/lucasepe/toolbox/secret"
+)
+
+func ExampleSecret() {
+	token := flags.Secret{
+		LookupEnv: func(key string) (string, bool) { return "s3cr3t", key == "API_TOKEN" },
+	}
+
+	var fs flag.FlagSet
+	fs.Var(&token, "token", "the API `token`")
+	fs.Parse([]string{"-token", "env:API_TOKEN"})
+
+	fmt.Println(token.Source(), token.Value())
+	fmt.Println(fs.Lookup("token").Value)
+	fmt.Printf("%v %#v\n", token, token)
+
+	// Output:
+	// env:API_TOKEN s3cr3t
+	// [REDACTED]
+	// [REDACTED] flags.Secret{[REDACTED]}
+}
+
+func TestSecretSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for v, want := range map[string]string{
+		"literal":      "literal",
+		"file:" + path: "from-file",
+		"-":            "from-stdin",
+	} {
+		fv := flags.Secret{Stdin: strings.NewReader("from-stdin\r\n")}
+		if err := fv.Set(v); err != nil || fv.Value() != want {
+			t.Errorf("%s: want %s, got %s (%v)", v, want, fv.Value(), err)
+		}
+	}
+
+	fv := flags.Secret{LookupEnv: func(string) (string, bool) { return "", false }}
+	for _, v := range []string{"env:MISSING", "file:" + path + ".missing", "prompt:"} {
+		if err := fv.Set(v); err == nil {
+			t.Errorf("%s: want error", v)
+		}
+	}
+}
+
+func TestSecretDecrypt(t *testing.T) {
+	enc, err := secret.Encrypt("key", []byte("plain"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, enc, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range []string{"file:" + path, base64.StdEncoding.EncodeToString(enc)} {
+		fv := flags.Secret{Key: "key"}
+		if err := fv.Set(v); err != nil || fv.Value() != "plain" {
+			t.Errorf("want the input decrypted, got %q (%v)", fv.Value(), err)
+		}
+	}
+
+	fv := flags.Secret{Key: "key"}
+	if err := fv.Set("not encrypted"); err != nil || fv.Value() != "not encrypted" {
+		t.Errorf("want plain inputs unchanged, got %q (%v)", fv.Value(), err)
+	}
+}
+
+func TestSecretRedacted(t *testing.T) {
+	fv := flags.Secret{}
+	fv.Set("hunter2")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.Var(&fv, "password", "the `password`")
+	fs.PrintDefaults()
+
+	if strings.Contains(out.String(), "hunter2") || !strings.Contains(out.String(), flags.Redacted) {
+		t.Fatalf("want the default redacted, got %q", out.String())
+	}
+
+	b := fv.Bytes()
+	fv.Clear()
+	if fv.IsSet() || fv.String() != "" || !bytes.Equal(b, make([]byte, len(b))) {
+		t.Fatal("want the secret cleared")
+	}
+}
@@ -0,0 +1,107 @@
+package flags
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a `flag.Value` for sizes in bytes, such as `512`, `10KB` or
+// `1.5GiB`. The units follow the GNU convention: `KB`, `MB`, `GB`, ... are
+// powers of 1000, while `K`, `KiB`, `M`, `MiB`, ... are powers of 1024.
+// The units are case-insensitive and the `B` suffix is optional.
+type ByteSize struct {
+	Value int64
+	Text  string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *ByteSize) Help() string {
+	return "a size in bytes, such as 512, 10KB or 10MiB"
+}
+
+// Set is flag.Value.Set
+func (fv *ByteSize) Set(v string) error {
+	n, err := parseByteSize(v)
+	if err != nil {
+		return err
+	}
+	fv.Value, fv.Text = n, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *ByteSize) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *ByteSize) String() string {
+	if fv == nil {
+		return ""
+	}
+	return formatByteSize(fv.Value)
+}
+
+var byteUnits = []string{"k", "m", "g", "t", "p", "e"}
+
+// parseByteSize parses v as a number of bytes followed by an optional unit.
+func parseByteSize(v string) (int64, error) {
+	s := strings.TrimSpace(v)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+
+	mult := float64(1)
+	if unit != "" && unit != "b" {
+		prefix, base := unit, float64(1024)
+		switch {
+		case strings.HasSuffix(unit, "ib"):
+			prefix = strings.TrimSuffix(unit, "ib")
+		case len(unit) == 2 && unit[1] == 'b':
+			prefix, base = unit[:1], 1000
+		}
+		exp := -1
+		for j, u := range byteUnits {
+			if prefix == u {
+				exp = j + 1
+			}
+		}
+		if exp < 0 {
+			return 0, fmt.Errorf(`"%s" has an unknown unit, want one of B, K, KB, KiB, M, MB, MiB, ...`, v)
+		}
+		mult = math.Pow(base, float64(exp))
+	}
+
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n > 0 && float64(n) > math.MaxInt64/mult {
+			return 0, fmt.Errorf(`"%s" is too large`, v)
+		}
+		return n * int64(mult), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf(`"%s" is not a valid size`, v)
+	}
+	if f*mult >= math.MaxInt64 {
+		return 0, fmt.Errorf(`"%s" is too large`, v)
+	}
+	return int64(f * mult), nil
+}
+
+// formatByteSize formats n with the largest binary unit dividing it.
+func formatByteSize(n int64) string {
+	unit := ""
+	for _, u := range byteUnits {
+		if n == 0 || n%1024 != 0 {
+			break
+		}
+		n /= 1024
+		unit = strings.ToUpper(u) + "i"
+	}
+	return strconv.FormatInt(n, 10) + unit + "B"
+}
@@ -0,0 +1,168 @@
+package flags
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Strings is a `flag.Value` for comma-separated string arguments.
+// If `Accumulate` is set, the values of all instances of the flag are accumulated.
+// The `Separator` field is used instead of the comma when set.
+type Strings struct {
+	Separator  string
+	Accumulate bool
+
+	Values []string
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Strings) Help() string {
+	return fmt.Sprintf("%q-separated list of values", separatorOf(fv.Separator))
+}
+
+// Set is flag.Value.Set
+func (fv *Strings) Set(v string) error {
+	values, err := splitValues(v, fv.Separator, fv.Accumulate, fv.Values, func(s string) (string, error) {
+		return s, nil
+	})
+	if err != nil {
+		return err
+	}
+	fv.Values = values
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Strings) Get() interface{} {
+	return fv.Values
+}
+
+func (fv *Strings) String() string {
+	if fv == nil {
+		return ""
+	}
+	return strings.Join(fv.Values, ",")
+}
+
+// Ints is a `flag.Value` for comma-separated integer arguments.
+// If `Accumulate` is set, the values of all instances of the flag are accumulated.
+// The `Separator` field is used instead of the comma when set.
+type Ints struct {
+	Separator  string
+	Accumulate bool
+
+	Values []int
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Ints) Help() string {
+	return fmt.Sprintf("%q-separated list of integers", separatorOf(fv.Separator))
+}
+
+// Set is flag.Value.Set
+func (fv *Ints) Set(v string) error {
+	values, err := splitValues(v, fv.Separator, fv.Accumulate, fv.Values, func(s string) (int, error) {
+		n, err := strconv.ParseInt(s, 0, strconv.IntSize)
+		if err != nil {
+			return 0, fmt.Errorf(`"%s" is not a valid integer`, s)
+		}
+		return int(n), nil
+	})
+	if err != nil {
+		return err
+	}
+	fv.Values = values
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Ints) Get() interface{} {
+	return fv.Values
+}
+
+func (fv *Ints) String() string {
+	if fv == nil {
+		return ""
+	}
+	parts := make([]string, len(fv.Values))
+	for i, n := range fv.Values {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// Durations is a `flag.Value` for comma-separated duration arguments,
+// such as `1m30s,2h`.
+// If `Accumulate` is set, the values of all instances of the flag are accumulated.
+// The `Separator` field is used instead of the comma when set.
+type Durations struct {
+	Separator  string
+	Accumulate bool
+
+	Values []time.Duration
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Durations) Help() string {
+	return fmt.Sprintf("%q-separated list of durations, such as 1m30s", separatorOf(fv.Separator))
+}
+
+// Set is flag.Value.Set
+func (fv *Durations) Set(v string) error {
+	values, err := splitValues(v, fv.Separator, fv.Accumulate, fv.Values, func(s string) (time.Duration, error) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf(`"%s" is not a valid duration`, s)
+		}
+		return d, nil
+	})
+	if err != nil {
+		return err
+	}
+	fv.Values = values
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *Durations) Get() interface{} {
+	return fv.Values
+}
+
+func (fv *Durations) String() string {
+	if fv == nil {
+		return ""
+	}
+	parts := make([]string, len(fv.Values))
+	for i, d := range fv.Values {
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// separatorOf returns separator, or the comma when it is empty.
+func separatorOf(separator string) string {
+	if separator == "" {
+		return ","
+	}
+	return separator
+}
+
+// splitValues parses the separator-separated parts of v, appending them
+// to values when accumulate is set, or else replacing them. values is
+// left untouched when a part is invalid.
+func splitValues[T any](v, separator string, accumulate bool, values []T, parse func(string) (T, error)) ([]T, error) {
+	var parsed []T
+	for _, part := range strings.Split(v, separatorOf(separator)) {
+		x, err := parse(strings.TrimSpace(part))
+		if err != nil {
+			return values, err
+		}
+		parsed = append(parsed, x)
+	}
+	if !accumulate {
+		return parsed, nil
+	}
+	return append(values, parsed...), nil
+}
@@ -0,0 +1,61 @@
+package flags
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// TextUnmarshaler is the constraint of the types of a `TextOf`: a pointer
+// to T implementing `encoding.TextUnmarshaler`.
+type TextUnmarshaler[T any] interface {
+	*T
+	encoding.TextUnmarshaler
+}
+
+// TextOf is a `flag.Value` adapting any type T whose pointer implements
+// `encoding.TextUnmarshaler`, such as `netip.Addr` or `big.Int`.
+// Create one with `NewTextOf`, which infers the type parameters.
+type TextOf[T any, P TextUnmarshaler[T]] struct {
+	Value T
+	Text  string
+}
+
+// NewTextOf returns a `TextOf` with the default value value.
+func NewTextOf[T any, P TextUnmarshaler[T]](value T) *TextOf[T, P] {
+	return &TextOf[T, P]{Value: value}
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *TextOf[T, P]) Help() string {
+	return fmt.Sprintf("a %T value", fv.Value)
+}
+
+// Set is flag.Value.Set
+func (fv *TextOf[T, P]) Set(v string) error {
+	var value T
+	if err := P(&value).UnmarshalText([]byte(v)); err != nil {
+		return fmt.Errorf(`"%s" is not a valid %T: %w`, v, value, err)
+	}
+	fv.Value, fv.Text = value, v
+	return nil
+}
+
+// Get is flag.Getter.Get
+func (fv *TextOf[T, P]) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *TextOf[T, P]) String() string {
+	if fv == nil {
+		return ""
+	}
+	switch v := interface{}(&fv.Value).(type) {
+	case encoding.TextMarshaler:
+		if b, err := v.MarshalText(); err == nil {
+			return string(b)
+		}
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fv.Text
+}
@@ -0,0 +1,67 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts are the layouts a `Time` is parsed with when its
+// `Layouts` field is empty.
+var DefaultTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Time is a `flag.Value` for timestamp arguments.
+// The value of the `Layouts` field defines the accepted layouts, tried in
+// order, and defaults to `DefaultTimeLayouts`. Timestamps without a time
+// zone are in `Location`, or else in the local time zone.
+type Time struct {
+	Layouts  []string
+	Location *time.Location
+
+	Value time.Time
+	Text  string
+}
+
+func (fv *Time) layouts() []string {
+	if len(fv.Layouts) > 0 {
+		return fv.Layouts
+	}
+	return DefaultTimeLayouts
+}
+
+// Help returns a string suitable for inclusion in a flag help message.
+func (fv *Time) Help() string {
+	return fmt.Sprintf("a timestamp in one of the layouts %q", fv.layouts())
+}
+
+// Set is flag.Value.Set
+func (fv *Time) Set(v string) error {
+	loc := fv.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	for _, layout := range fv.layouts() {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(v), loc); err == nil {
+			fv.Value, fv.Text = t, v
+			return nil
+		}
+	}
+	return fmt.Errorf(`"%s" must be a timestamp in one of the layouts %q`, v, fv.layouts())
+}
+
+// Get is flag.Getter.Get
+func (fv *Time) Get() interface{} {
+	return fv.Value
+}
+
+func (fv *Time) String() string {
+	if fv == nil || fv.Value.IsZero() {
+		return ""
+	}
+	return fv.Value.Format(fv.layouts()[0])
+}
@@ -0,0 +1,250 @@
+package flags_test
+
+import (
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasepe/toolbox/flags"
+)
+
+func ExampleByteSize() {
+	size := flags.ByteSize{}
+	size.Set("1MiB")
+	labels := flags.Map{}
+	ports := flags.Ints{Accumulate: true}
+	workers := flags.NewRange(1, 16)
+
+	var fs flag.FlagSet
+	fs.Var(&size, "size", "the buffer size")
+	fs.Var(&labels, "label", "add a label")
+	fs.Var(&ports, "ports", "the ports to listen on")
+	fs.Var(workers, "workers", "the number of workers")
+
+	fs.Parse([]string{
+		"-size", "10KB",
+		"-label", "env=prod",
+		"-label", "tier=web",
+		"-ports", "80,443",
+		"-ports", "8080",
+		"-workers", "4",
+	})
+
+	fmt.Println("size:", size.Value)
+	fmt.Println("labels:", labels.String())
+	fmt.Println("ports:", ports.Values)
+	fmt.Println("workers:", workers.Value)
+
+	// Output:
+	// size: 10000
+	// labels: env=prod,tier=web
+	// ports: [80 443 8080]
+	// workers: 4
+}
+
+func TestByteSize(t *testing.T) {
+	for v, want := range map[string]int64{
+		"512":    512,
+		"2k":     2048,
+		"2KB":    2000,
+		"2KiB":   2048,
+		"1.5 GB": 1500000000,
+		"10MiB":  10 << 20,
+		"1e":     1 << 60,
+	} {
+		var fv flags.ByteSize
+		if err := fv.Set(v); err != nil || fv.Value != want {
+			t.Errorf("%s: want %d, got %d (%v)", v, want, fv.Value, err)
+		}
+	}
+	for _, v := range []string{"", "MiB", "10XB", "1.2.3", "16EiB"} {
+		var fv flags.ByteSize
+		if err := fv.Set(v); err == nil {
+			t.Errorf("%s: want error, got %d", v, fv.Value)
+		}
+	}
+
+	fv := flags.ByteSize{Value: 3 << 20}
+	if got := fv.String(); got != "3MiB" {
+		t.Fatalf("want 3MiB, got %s", got)
+	}
+}
+
+func TestSlices(t *testing.T) {
+	strs := flags.Strings{Separator: ";"}
+	durs := flags.Durations{Accumulate: true}
+
+	var fs flag.FlagSet
+	fs.Var(&strs, "strs", "")
+	fs.Var(&durs, "durs", "")
+
+	err := fs.Parse([]string{"-strs", "a;b", "-strs", "c; d", "-durs", "1s,1m", "-durs", "1h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(strs.Values, []string{"c", "d"}) {
+		t.Fatalf("want the last values, got %v", strs.Values)
+	}
+	if durs.String() != "1s,1m0s,1h0m0s" {
+		t.Fatalf("want the values accumulated, got %v", durs.Values)
+	}
+
+	ints := flags.Ints{Values: []int{1}}
+	if err := ints.Set("2,x"); err == nil || !reflect.DeepEqual(ints.Values, []int{1}) {
+		t.Fatalf("want the values untouched on error, got %v (%v)", ints.Values, err)
+	}
+}
+
+func TestMap(t *testing.T) {
+	fv := flags.Map{Separator: ","}
+	if err := fv.Set("a=1,b=x=y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fv.Set("a=2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fv.String(); got != "a=2,b=x=y" {
+		t.Fatalf("want a=2,b=x=y, got %s", got)
+	}
+	if err := fv.Set("c"); err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestNetValues(t *testing.T) {
+	u := flags.URL{Schemes: []string{"http", "https"}}
+	if err := u.Set("HTTPS://example.com/x"); err != nil || u.Value.Host != "example.com" {
+		t.Fatalf("unexpected URL %v (%v)", u.Value, err)
+	}
+	for _, v := range []string{"ftp://example.com", "example.com"} {
+		if err := u.Set(v); err == nil {
+			t.Errorf("%s: want error", v)
+		}
+	}
+
+	var ip flags.IP
+	if err := ip.Set("::1"); err != nil || !ip.Value.IsLoopback() {
+		t.Fatalf("unexpected IP %v (%v)", ip.Value, err)
+	}
+
+	var cidr flags.CIDR
+	if err := cidr.Set("192.0.2.1/24"); err != nil {
+		t.Fatal(err)
+	}
+	if cidr.Value.String() != "192.0.2.0/24" || cidr.String() != "192.0.2.1/24" {
+		t.Fatalf("unexpected CIDR %v %v", cidr.Value, cidr.String())
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	var fv flags.Regexp
+	if err := fv.Set("^a+$"); err != nil || !fv.Value.MatchString("aaa") {
+		t.Fatalf("unexpected regexp %v (%v)", fv.Value, err)
+	}
+	if err := fv.Set("a("); err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestTime(t *testing.T) {
+	fv := flags.Time{Location: time.UTC}
+	if err := fv.Set("2024-02-29"); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC); !fv.Value.Equal(want) {
+		t.Fatalf("want %v, got %v", want, fv.Value)
+	}
+	if err := fv.Set("2024-02-29T10:00:00+01:00"); err != nil || fv.Value.Hour() != 10 {
+		t.Fatalf("unexpected time %v (%v)", fv.Value, err)
+	}
+
+	fv = flags.Time{Layouts: []string{"02/01/2006"}}
+	if err := fv.Set("2024-02-29"); err == nil || !strings.Contains(err.Error(), "02/01/2006") {
+		t.Fatalf("want the layouts in the error, got %v", err)
+	}
+}
+
+func TestPath(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	for _, tc := range []struct {
+		fv   flag.Value
+		path string
+		ok   bool
+	}{
+		{&flags.File{MustExist: true, Readable: true, Writable: true}, file, true},
+		{&flags.File{MustExist: true}, missing, false},
+		{&flags.File{Writable: true}, missing, true},
+		{&flags.File{Writable: true}, filepath.Join(missing, "file"), false},
+		{&flags.File{}, dir, false},
+		{&flags.Dir{MustExist: true, Readable: true, Writable: true}, dir, true},
+		{&flags.Dir{}, file, false},
+	} {
+		if err := tc.fv.Set(tc.path); (err == nil) != tc.ok {
+			t.Errorf("%T %s: unexpected error %v", tc.fv, tc.path, err)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	fv := flags.NewRange(-1.5, 1.5)
+	if err := fv.Set("0.5"); err != nil || fv.Value != 0.5 {
+		t.Fatalf("unexpected value %v (%v)", fv.Value, err)
+	}
+	if err := fv.Set("2"); err == nil || fv.Value != 0.5 {
+		t.Fatalf("want an out of bounds error, got %v", err)
+	}
+
+	var u8 flags.Range[uint8]
+	if err := u8.Set("0xff"); err != nil || u8.Value != 255 {
+		t.Fatalf("unexpected value %v (%v)", u8.Value, err)
+	}
+	if err := u8.Set("256"); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("want an overflow error, got %v", err)
+	}
+}
+
+func TestTextOf(t *testing.T) {
+	fv := flags.NewTextOf(netip.MustParseAddr("127.0.0.1"))
+	var fs flag.FlagSet
+	fs.Var(fv, "addr", fv.Help())
+
+	if err := fs.Parse([]string{"-addr", "::1"}); err != nil {
+		t.Fatal(err)
+	}
+	if fv.Value != netip.IPv6Loopback() || fv.String() != "::1" {
+		t.Fatalf("unexpected value %v", fv.Value)
+	}
+	if got := fv.Help(); got != "a netip.Addr value" {
+		t.Fatalf("unexpected help %q", got)
+	}
+	if err := fv.Set("nope"); err == nil {
+		t.Fatal("want error")
+	}
+}
+
+func TestHelp(t *testing.T) {
+	for _, fv := range []interface{ Help() string }{
+		&flags.ByteSize{}, &flags.Strings{}, &flags.Ints{}, &flags.Durations{},
+		&flags.Map{}, &flags.URL{}, &flags.IP{}, &flags.CIDR{}, &flags.Regexp{},
+		&flags.Time{}, &flags.File{}, &flags.Dir{}, &flags.Range[int]{},
+	} {
+		if fv.Help() == "" {
+			t.Errorf("%T: want a help string", fv)
+		}
+	}
+	if got := (&flags.File{MustExist: true, Writable: true}).Help(); got != "a file path (existing, writable)" {
+		t.Fatalf("unexpected help %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package text
+
+import (
+	"sort"
+	"strings"
+)
+
+// Distance returns the edit distance between a and b, that is the
+// minimum number of single-rune insertions, deletions, substitutions
+// and transpositions of adjacent runes needed to turn a into b.
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// Suggest returns the candidates that are at most maxDistance edits away
+// from s, or that have s as a prefix, ordered from the closest match.
+// The comparison is case-insensitive. A maxDistance less than or equal
+// to zero selects a default based on the length of s.
+func Suggest(s string, candidates []string, maxDistance int) []string {
+	if maxDistance <= 0 {
+		maxDistance = len([]rune(s)) / 3
+		if maxDistance < 1 {
+			maxDistance = 1
+		}
+	}
+
+	type match struct {
+		value string
+		dist  int
+	}
+	var matches []match
+	seen := make(map[string]bool)
+	ls := strings.ToLower(s)
+	for _, c := range candidates {
+		if seen[c] || c == "" {
+			continue
+		}
+		lc := strings.ToLower(c)
+		dist := Distance(ls, lc)
+		if dist <= maxDistance || (ls != "" && strings.HasPrefix(lc, ls)) {
+			seen[c] = true
+			matches = append(matches, match{value: c, dist: dist})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].dist < matches[j].dist
+	})
+
+	res := make([]string, 0, len(matches))
+	for _, m := range matches {
+		res = append(res, m.value)
+	}
+	return res
+}
+
+func minInt(a int, rest ...int) int {
+	for _, v := range rest {
+		if v < a {
+			a = v
+		}
+	}
+	return a
+}
@@ -0,0 +1,46 @@
+package text_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lucasepe/toolbox/text"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"stauts", "status", 1},
+		{"ca", "abc", 3},
+		{"héllo", "hello", 1},
+	}
+	for _, tt := range tests {
+		if got := text.Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%q, %q): want %d, got %d", tt.a, tt.b, tt.want, got)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"status", "stash", "commit", "config", "STATE"}
+
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"stauts", []string{"status", "STATE"}},
+		{"stat", []string{"STATE", "status"}},
+		{"comit", []string{"commit"}},
+		{"xyz", []string{}},
+	}
+	for _, tt := range tests {
+		if got := text.Suggest(tt.s, candidates, 0); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q): want %q, got %q", tt.s, tt.want, got)
+		}
+	}
+}